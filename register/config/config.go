@@ -2,28 +2,229 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Kafka Connect operations with their own timeout and retry policy
+const (
+	ConnectRead     = "read"     // list, status and config lookups
+	ConnectCreate   = "create"   // register and re-create connectors
+	ConnectUpdate   = "update"   // config updates
+	ConnectValidate = "validate" // plugin config validation
+	ConnectControl  = "control"  // pause, resume and restarts
+	ConnectDelete   = "delete"
+)
+
+type ConnectPolicy struct {
+	Timeout   time.Duration // per attempt
+	Retries   int
+	RetryWait time.Duration // doubled after every retry
+}
+
 type Config struct {
 	Port         string
 	ConnectorUrl string
 	DatabaseURL  string
 	LogLevel     string
+
+	ConnectPolicies map[string]ConnectPolicy // timeout and retries by Kafka Connect operation
+
+	ReconcileInterval time.Duration
+
+	RegistrationTimeout      time.Duration // how long to wait for a new connector's tasks to settle
+	RegistrationPollInterval time.Duration
+	OperationRetention       time.Duration // how long finished operations stay queryable
+	AllowSkipPreflight       bool          // lets register requests set skip_preflight
+
+	WebhooksFile        string // enables the status watcher and webhook notifications
+	StatusWatchInterval time.Duration
+
+	AutoHealInterval    time.Duration // 0 disables restarting failed tasks
+	AutoHealMaxAttempts int
+	AutoHealBackoff     time.Duration // doubled after every restart of the same task
+	AutoHealMaxBackoff  time.Duration
+	AutoHealStableAfter time.Duration // how long a restarted task has to keep RUNNING before its attempts are forgotten
+
+	MetricsRefreshInterval time.Duration // how often connector and task state gauges are refreshed, 0 disables them
+
+	TracingExporter    string // none, otlp or stdout
+	TracingServiceName string
+	TracingSampleRatio float64
+
+	SecretsBackend    string // file, kubernetes or none
+	SecretsDir        string // relative to the working directory unless absolute
+	SecretsMountPath  string // where Kafka Connect workers see the secrets
+	SecretsNamespace  string
+	SecretsSecretName string
+
+	RedactPatterns []string // added to the default sensitive key patterns
+
+	APIKeys     map[string]string // key -> principal name
+	JWKSFile    string
+	JWTIssuer   string
+	JWTAudience string
+
+	RBACPolicyFile string
+
+	TenantsFile string // enables multi-tenancy
+
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string // enables client certificate authentication
 }
 
 func Load() *Config {
 	cfg := &Config{
 		Port:         getEnvOrDefault("PORT", "8080"),
 		ConnectorUrl: getEnvOrDefault("KAFKA_CONNECT_URL", "http://192.168.49.2:31994"),
+		DatabaseURL:  getEnvOrDefault("DATABASE_URL", "host=localhost user=postgres password=postgres dbname=cdc_registry port=5432 sslmode=disable"),
 		LogLevel:     getEnvOrDefault("LOG_LEVEL", "info"),
+
+		ReconcileInterval: getDurationOrDefault("RECONCILE_INTERVAL", time.Minute),
+
+		RegistrationTimeout:      getDurationOrDefault("REGISTRATION_TIMEOUT", 5*time.Minute),
+		RegistrationPollInterval: getDurationOrDefault("REGISTRATION_POLL_INTERVAL", 2*time.Second),
+		OperationRetention:       getDurationOrDefault("OPERATION_RETENTION", time.Hour),
+		AllowSkipPreflight:       getBoolOrDefault("ALLOW_SKIP_PREFLIGHT", false),
+
+		WebhooksFile:        getEnvOrDefault("WEBHOOKS_FILE", ""),
+		StatusWatchInterval: getDurationOrDefault("STATUS_WATCH_INTERVAL", 30*time.Second),
+
+		AutoHealInterval:    getDurationOrDefault("AUTO_HEAL_INTERVAL", 0),
+		AutoHealMaxAttempts: getIntOrDefault("AUTO_HEAL_MAX_ATTEMPTS", 5),
+		AutoHealBackoff:     getDurationOrDefault("AUTO_HEAL_BACKOFF", 30*time.Second),
+		AutoHealMaxBackoff:  getDurationOrDefault("AUTO_HEAL_MAX_BACKOFF", 10*time.Minute),
+		AutoHealStableAfter: getDurationOrDefault("AUTO_HEAL_STABLE_AFTER", 10*time.Minute),
+
+		MetricsRefreshInterval: getDurationOrDefault("METRICS_REFRESH_INTERVAL", 30*time.Second),
+
+		TracingExporter:    getEnvOrDefault("TRACING_EXPORTER", "none"),
+		TracingServiceName: getEnvOrDefault("OTEL_SERVICE_NAME", "cdc-registration"),
+		TracingSampleRatio: getFloatOrDefault("TRACING_SAMPLE_RATIO", 1),
+
+		SecretsBackend:    getEnvOrDefault("SECRETS_BACKEND", "file"),
+		SecretsDir:        getEnvOrDefault("SECRETS_DIR", "./secrets"),
+		SecretsMountPath:  getEnvOrDefault("SECRETS_MOUNT_PATH", "/secrets"),
+		SecretsNamespace:  getEnvOrDefault("SECRETS_NAMESPACE", ""),
+		SecretsSecretName: getEnvOrDefault("SECRETS_SECRET_NAME", "cdc-connector-secrets"),
+
+		RedactPatterns: getListOrDefault("REDACT_KEY_PATTERNS", nil),
+
+		APIKeys:     getAPIKeys("API_KEYS"),
+		JWKSFile:    getEnvOrDefault("JWKS_FILE", ""),
+		JWTIssuer:   getEnvOrDefault("JWT_ISSUER", ""),
+		JWTAudience: getEnvOrDefault("JWT_AUDIENCE", ""),
+
+		RBACPolicyFile: getEnvOrDefault("RBAC_POLICY_FILE", ""),
+
+		TenantsFile: getEnvOrDefault("TENANTS_FILE", ""),
+
+		TLSCertFile:     getEnvOrDefault("TLS_CERT_FILE", ""),
+		TLSKeyFile:      getEnvOrDefault("TLS_KEY_FILE", ""),
+		TLSClientCAFile: getEnvOrDefault("TLS_CLIENT_CA_FILE", ""),
 	}
 
+	cfg.ConnectPolicies = getConnectPolicies()
+
 	return cfg
 }
 
+// getConnectPolicies reads KAFKA_CONNECT_TIMEOUT, KAFKA_CONNECT_RETRIES and
+// KAFKA_CONNECT_RETRY_WAIT, which operations override with variables like
+// KAFKA_CONNECT_READ_TIMEOUT or KAFKA_CONNECT_CREATE_RETRIES
+func getConnectPolicies() map[string]ConnectPolicy {
+	defaults := ConnectPolicy{
+		Timeout:   getDurationOrDefault("KAFKA_CONNECT_TIMEOUT", 30*time.Second),
+		Retries:   getIntOrDefault("KAFKA_CONNECT_RETRIES", 3),
+		RetryWait: getDurationOrDefault("KAFKA_CONNECT_RETRY_WAIT", 5*time.Second),
+	}
+
+	policies := make(map[string]ConnectPolicy)
+	for _, operation := range []string{ConnectRead, ConnectCreate, ConnectUpdate, ConnectValidate, ConnectControl, ConnectDelete} {
+		policy := defaults
+		if operation == ConnectCreate {
+			// A create that timed out may still have succeeded, retrying it would fail with 409
+			policy.Retries = 0
+		}
+
+		prefix := "KAFKA_CONNECT_" + strings.ToUpper(operation) + "_"
+		policies[operation] = ConnectPolicy{
+			Timeout:   getDurationOrDefault(prefix+"TIMEOUT", policy.Timeout),
+			Retries:   getIntOrDefault(prefix+"RETRIES", policy.Retries),
+			RetryWait: getDurationOrDefault(prefix+"RETRY_WAIT", policy.RetryWait),
+		}
+	}
+	return policies
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+func getDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+func getIntOrDefault(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func getBoolOrDefault(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+func getFloatOrDefault(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+func getListOrDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getAPIKeys parses "name:key" pairs separated by commas
+func getAPIKeys(key string) map[string]string {
+	keys := make(map[string]string)
+	for _, pair := range getListOrDefault(key, nil) {
+		name, apiKey, ok := strings.Cut(pair, ":")
+		if !ok || name == "" || apiKey == "" {
+			continue
+		}
+		keys[apiKey] = name
+	}
+	return keys
+}
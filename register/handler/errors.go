@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"register/pkg/apierror"
+	"register/pkg/connect"
+	registerhttp "register/pkg/http"
+	"register/preflight"
+	"register/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// abortWithError answers with the status and error code err maps to, with
+// credentials quoted by Kafka Connect or database drivers masked
+func (h *cDCHandler) abortWithError(c *gin.Context, err error) {
+	status, apiErr := classify(err)
+	apiErr.Message = h.redact.Text(apiErr.Message)
+	apierror.Abort(c, status, apiErr)
+}
+
+// abortWith answers with a request error that has no underlying error value
+func abortWith(c *gin.Context, status int, code, message string) {
+	apierror.Abort(c, status, apierror.Error{Code: code, Message: message})
+}
+
+func classify(err error) (int, apierror.Error) {
+	apiErr := apierror.Error{Message: err.Error()}
+
+	var validationErr *service.ValidationError
+	var preflightErr *service.PreflightError
+	var connectErr *connect.Error
+	var transportErr *registerhttp.TransportError
+	switch {
+	case errors.As(err, &validationErr):
+		apiErr.Code, apiErr.Details = apierror.CodeValidationFailed, validationErr.Result
+		return http.StatusUnprocessableEntity, apiErr
+	case errors.As(err, &preflightErr):
+		apiErr.Code, apiErr.Details = apierror.CodePreflightFailed, preflightErr.Report
+		return http.StatusUnprocessableEntity, apiErr
+	case errors.Is(err, service.ErrConnectorAlreadyRegistered), errors.Is(err, service.ErrConnectorExists),
+		errors.Is(err, service.ErrTopicPrefixInUse):
+		apiErr.Code = apierror.CodeConflict
+		return http.StatusConflict, apiErr
+	case errors.Is(err, service.ErrQuotaExceeded):
+		apiErr.Code = apierror.CodeQuotaExceeded
+		return http.StatusForbidden, apiErr
+	case errors.Is(err, service.ErrUnknownTenant), errors.Is(err, service.ErrTopicPrefixNotAllowed),
+		errors.Is(err, service.ErrSkipPreflightNotAllowed):
+		apiErr.Code = apierror.CodeForbidden
+		return http.StatusForbidden, apiErr
+	case errors.Is(err, service.ErrConnectorNotRegistered), errors.Is(err, service.ErrOperationNotFound),
+		errors.Is(err, service.ErrNoReconciliationReport):
+		apiErr.Code = apierror.CodeNotFound
+		return http.StatusNotFound, apiErr
+	case errors.Is(err, preflight.ErrUnsupportedDatabase):
+		apiErr.Code = apierror.CodeInvalidRequest
+		return http.StatusBadRequest, apiErr
+	case errors.As(err, &connectErr):
+		apiErr.UpstreamStatus = connectErr.StatusCode
+		status, code := classifyConnect(connectErr)
+		apiErr.Code = code
+		return status, apiErr
+	case errors.As(err, &transportErr), errors.Is(err, context.DeadlineExceeded):
+		apiErr.Code = apierror.CodeUpstreamUnavailable
+		return http.StatusServiceUnavailable, apiErr
+	default:
+		apiErr.Code = apierror.CodeInternal
+		return http.StatusInternalServerError, apiErr
+	}
+}
+
+// classifyConnect passes on what the caller can act on, a bad config, a missing
+// or existing connector, and reports everything else as a gateway error
+func classifyConnect(err *connect.Error) (int, string) {
+	switch {
+	case err.StatusCode == http.StatusBadRequest:
+		return http.StatusBadRequest, apierror.CodeInvalidRequest
+	case err.StatusCode == http.StatusNotFound:
+		return http.StatusNotFound, apierror.CodeNotFound
+	case connect.IsRebalancing(err):
+		return http.StatusServiceUnavailable, apierror.CodeUpstreamUnavailable
+	case err.StatusCode == http.StatusConflict:
+		return http.StatusConflict, apierror.CodeConflict
+	case err.StatusCode == http.StatusServiceUnavailable:
+		return http.StatusServiceUnavailable, apierror.CodeUpstreamUnavailable
+	default:
+		return http.StatusBadGateway, apierror.CodeUpstreamError
+	}
+}
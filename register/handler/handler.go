@@ -1,29 +1,52 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"register/models"
+	"register/pkg/apierror"
+	"register/pkg/auth"
 	"register/pkg/logger"
+	"register/pkg/redact"
+	"register/pkg/tenant"
+	"register/pkg/webhook"
 	"register/service"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type CDCHandler interface {
 	RegisterConnector(c *gin.Context)
+	ValidateConnector(c *gin.Context)
+	Preflight(c *gin.Context)
+	UpdateConnectorConfig(c *gin.Context)
 	ListConnectors(c *gin.Context)
 	GetConnectorStatus(c *gin.Context)
+	PauseConnector(c *gin.Context)
+	ResumeConnector(c *gin.Context)
+	RestartConnector(c *gin.Context)
+	RestartTask(c *gin.Context)
 	DeleteConnector(c *gin.Context)
+	GetReconciliationReport(c *gin.Context)
+	GetTenantUsage(c *gin.Context)
+	GetOperation(c *gin.Context)
+	GetWebhookDeliveries(c *gin.Context)
+	GetHealingReport(c *gin.Context)
 }
 type cDCHandler struct {
-	service service.CDCRegistrationService
-	logger  logger.Logger
+	service  service.CDCRegistrationService
+	webhooks webhook.Dispatcher // nil when webhooks are not configured
+	redact   *redact.Policy
+	logger   logger.Logger
 }
 
-func NewCDCHandler(service service.CDCRegistrationService, logger logger.Logger) CDCHandler {
+func NewCDCHandler(service service.CDCRegistrationService, webhooks webhook.Dispatcher, policy *redact.Policy, logger logger.Logger) CDCHandler {
 	return &cDCHandler{
-		service: service,
-		logger:  logger,
+		service:  service,
+		webhooks: webhooks,
+		redact:   policy,
+		logger:   logger,
 	}
 }
 
@@ -31,30 +54,107 @@ func (h *cDCHandler) RegisterConnector(c *gin.Context) {
 	var req models.RegisterConnectorRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Error("Invalid request payload", logger.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		abortWith(c, http.StatusBadRequest, apierror.CodeInvalidRequest, bindingError(err))
 		return
 	}
+	qualifyRequest(c, &req)
+	req.CreatedBy = auth.SubjectFrom(c)
 
-	h.logger.Info("Registering connector", logger.String("connector_name", req.ConnectorName))
+	h.logger.Info("Registering connector",
+		logger.String("connector_name", req.ConnectorName),
+		logger.String("principal", req.CreatedBy),
+	)
 
-	response, err := h.service.RegisterConnector(req)
+	response, err := h.service.RegisterConnector(c.Request.Context(), req)
 	if err != nil {
 		h.logger.Error("Failed to register connector", logger.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.abortWithError(c, err)
 		return
 	}
 
-	h.logger.Info("Connector registered successfully", logger.String("connector_name", req.ConnectorName))
-	c.JSON(http.StatusCreated, response)
+	h.logger.Info("Connector registration accepted",
+		logger.String("connector_name", req.ConnectorName),
+		logger.String("operation_id", response.OperationID),
+	)
+	c.Header("Location", "/api/operations/"+response.OperationID)
+	c.JSON(http.StatusAccepted, response)
+}
+
+func (h *cDCHandler) ValidateConnector(c *gin.Context) {
+	var req models.RegisterConnectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid request payload", logger.Error(err))
+		abortWith(c, http.StatusBadRequest, apierror.CodeInvalidRequest, bindingError(err))
+		return
+	}
+	qualifyRequest(c, &req)
+
+	h.logger.Info("Validating connector", logger.String("connector_name", req.ConnectorName))
+
+	result, err := h.service.ValidateConnector(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to validate connector", logger.Error(err))
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *cDCHandler) Preflight(c *gin.Context) {
+	var req models.RegisterConnectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid request payload", logger.Error(err))
+		abortWith(c, http.StatusBadRequest, apierror.CodeInvalidRequest, bindingError(err))
+		return
+	}
+
+	h.logger.Info("Running pre-flight checks", logger.String("database_host", req.DatabaseHost))
+
+	report, err := h.service.Preflight(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to run pre-flight checks", logger.Error(err))
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *cDCHandler) UpdateConnectorConfig(c *gin.Context) {
+	connectorName := c.Param("name")
+
+	var req models.UpdateConnectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid request payload", logger.Error(err))
+		abortWith(c, http.StatusBadRequest, apierror.CodeInvalidRequest, bindingError(err))
+		return
+	}
+
+	if req.TopicPrefix != "" {
+		req.TopicPrefix = tenant.Qualify(tenant.NameFrom(c), req.TopicPrefix)
+	}
+
+	h.logger.Info("Updating connector config", logger.String("connector_name", connectorName))
+
+	response, err := h.service.UpdateConnectorConfig(c.Request.Context(), connectorName, req)
+	if err != nil {
+		h.logger.Error("Failed to update connector config", logger.Error(err))
+		h.abortWithError(c, err)
+		return
+	}
+
+	h.logger.Info("Connector config updated successfully", logger.String("connector_name", connectorName))
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *cDCHandler) ListConnectors(c *gin.Context) {
 	h.logger.Info("Listing connectors")
 
-	response, err := h.service.ListConnectors()
+	response, err := h.service.ListConnectors(c.Request.Context(), tenant.NameFrom(c))
 	if err != nil {
 		h.logger.Error("Failed to list connectors", logger.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.abortWithError(c, err)
 		return
 	}
 
@@ -66,10 +166,86 @@ func (h *cDCHandler) GetConnectorStatus(c *gin.Context) {
 
 	h.logger.Info("Getting connector status", logger.String("connector_name", connectorName))
 
-	status, err := h.service.GetConnectorStatus(connectorName)
+	status, err := h.service.GetConnectorStatus(c.Request.Context(), connectorName)
 	if err != nil {
 		h.logger.Error("Failed to get connector status", logger.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *cDCHandler) PauseConnector(c *gin.Context) {
+	connectorName := c.Param("name")
+
+	h.logger.Info("Pausing connector", logger.String("connector_name", connectorName))
+
+	status, err := h.service.PauseConnector(c.Request.Context(), connectorName)
+	if err != nil {
+		h.logger.Error("Failed to pause connector", logger.Error(err))
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *cDCHandler) ResumeConnector(c *gin.Context) {
+	connectorName := c.Param("name")
+
+	h.logger.Info("Resuming connector", logger.String("connector_name", connectorName))
+
+	status, err := h.service.ResumeConnector(c.Request.Context(), connectorName)
+	if err != nil {
+		h.logger.Error("Failed to resume connector", logger.Error(err))
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *cDCHandler) RestartConnector(c *gin.Context) {
+	connectorName := c.Param("name")
+
+	var req models.RestartConnectorRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Error("Invalid restart options", logger.Error(err))
+		abortWith(c, http.StatusBadRequest, apierror.CodeInvalidRequest, bindingError(err))
+		return
+	}
+
+	h.logger.Info("Restarting connector", logger.String("connector_name", connectorName))
+
+	status, err := h.service.RestartConnector(c.Request.Context(), connectorName, req)
+	if err != nil {
+		h.logger.Error("Failed to restart connector", logger.Error(err))
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *cDCHandler) RestartTask(c *gin.Context) {
+	connectorName := c.Param("name")
+
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || taskID < 0 {
+		abortWith(c, http.StatusBadRequest, apierror.CodeInvalidRequest, "task id must be a non-negative integer")
+		return
+	}
+
+	h.logger.Info("Restarting connector task",
+		logger.String("connector_name", connectorName),
+		logger.Int("task_id", taskID),
+	)
+
+	status, err := h.service.RestartTask(c.Request.Context(), connectorName, taskID)
+	if err != nil {
+		h.logger.Error("Failed to restart connector task", logger.Error(err))
+		h.abortWithError(c, err)
 		return
 	}
 
@@ -79,14 +255,92 @@ func (h *cDCHandler) GetConnectorStatus(c *gin.Context) {
 func (h *cDCHandler) DeleteConnector(c *gin.Context) {
 	connectorName := c.Param("name")
 
-	h.logger.Info("Deleting connector", logger.String("connector_name", connectorName))
+	h.logger.Info("Deleting connector",
+		logger.String("connector_name", connectorName),
+		logger.String("principal", auth.SubjectFrom(c)),
+	)
 
-	if err := h.service.DeleteConnector(connectorName); err != nil {
+	if err := h.service.DeleteConnector(c.Request.Context(), connectorName); err != nil {
 		h.logger.Error("Failed to delete connector", logger.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.abortWithError(c, err)
 		return
 	}
 
 	h.logger.Info("Connector deleted successfully", logger.String("connector_name", connectorName))
 	c.JSON(http.StatusOK, gin.H{"message": "Connector deleted successfully"})
 }
+
+func (h *cDCHandler) GetReconciliationReport(c *gin.Context) {
+	report, err := h.service.GetReconciliationReport(c.Request.Context(), tenant.NameFrom(c))
+	if err != nil {
+		if !errors.Is(err, service.ErrNoReconciliationReport) {
+			h.logger.Error("Failed to get reconciliation report", logger.Error(err))
+		}
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *cDCHandler) GetTenantUsage(c *gin.Context) {
+	tenantName := tenant.NameFrom(c)
+	if tenantName == "" {
+		abortWith(c, http.StatusNotFound, apierror.CodeNotFound, "multi-tenancy is not enabled")
+		return
+	}
+
+	usage, err := h.service.GetTenantUsage(c.Request.Context(), tenantName)
+	if err != nil {
+		h.logger.Error("Failed to get tenant usage", logger.Error(err))
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+func (h *cDCHandler) GetOperation(c *gin.Context) {
+	operation, err := h.service.GetOperation(c.Request.Context(), c.Param("id"), tenant.NameFrom(c))
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, operation)
+}
+
+func (h *cDCHandler) GetWebhookDeliveries(c *gin.Context) {
+	if h.webhooks == nil {
+		abortWith(c, http.StatusNotFound, apierror.CodeNotFound, "webhooks are not configured")
+		return
+	}
+
+	tenantName := tenant.NameFrom(c)
+	deliveries := []webhook.Delivery{}
+	for _, d := range h.webhooks.Deliveries() {
+		if tenantName == "" || tenant.Owns(tenantName, d.Connector) {
+			deliveries = append(deliveries, d)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+func (h *cDCHandler) GetHealingReport(c *gin.Context) {
+	report, err := h.service.GetHealingReport(c.Request.Context(), tenant.NameFrom(c))
+	if err != nil {
+		h.logger.Error("Failed to get auto-heal report", logger.Error(err))
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// qualifyRequest namespaces the connector name and topic prefix with the caller's tenant
+func qualifyRequest(c *gin.Context, req *models.RegisterConnectorRequest) {
+	req.Tenant = tenant.NameFrom(c)
+	req.ConnectorName = tenant.Qualify(req.Tenant, req.ConnectorName)
+	req.TopicPrefix = tenant.Qualify(req.Tenant, req.TopicPrefix)
+}
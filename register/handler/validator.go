@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"register/models"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterValidators adds the custom binding tags used by the request models
+func RegisterValidators() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unexpected gin validator engine")
+	}
+
+	if err := v.RegisterValidation("database_type", func(fl validator.FieldLevel) bool {
+		return models.Database(fl.Field().String()).IsValid()
+	}); err != nil {
+		return err
+	}
+	if err := v.RegisterValidation("connector_name", func(fl validator.FieldLevel) bool {
+		return models.ValidConnectorName(fl.Field().String())
+	}); err != nil {
+		return err
+	}
+	return v.RegisterValidation("transform_key", func(fl validator.FieldLevel) bool {
+		return models.ValidTransformKey(fl.Field().String())
+	})
+}
+
+// bindingError turns validation failures of custom tags into readable messages
+func bindingError(err error) string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err.Error()
+	}
+
+	for _, fe := range validationErrs {
+		switch fe.Tag() {
+		case "database_type":
+			return fmt.Sprintf("unsupported database_type %q, supported types: %s", fe.Value(), supportedDatabases())
+		case "connector_name":
+			return fmt.Sprintf("invalid connector_name %q, use letters, digits, '.', '_' and '-' only", fe.Value())
+		case "transform_key":
+			return fmt.Sprintf("invalid transforms key %q, only transforms.* and predicates.* keys are allowed", fe.Value())
+		}
+	}
+	return err.Error()
+}
+
+func supportedDatabases() string {
+	var types []string
+	for _, db := range models.SupportedDatabases() {
+		aliases := models.DatabaseAliases(db)
+		if len(aliases) == 0 {
+			types = append(types, string(db))
+			continue
+		}
+		sort.Strings(aliases)
+		types = append(types, fmt.Sprintf("%s (aliases: %s)", db, strings.Join(aliases, ", ")))
+	}
+	return strings.Join(types, ", ")
+}
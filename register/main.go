@@ -1,13 +1,31 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
+	nethttp "net/http"
 	"os"
 	"register/config"
 	"register/handler"
+	"register/models"
+	"register/pkg/auth"
+	"register/pkg/connect"
+	"register/pkg/db"
 	"register/pkg/http"
 	"register/pkg/logger"
+	"register/pkg/rbac"
+	"register/pkg/redact"
+	"register/pkg/secrets"
+	"register/pkg/tenant"
+	"register/pkg/tracing"
+	"register/pkg/webhook"
+	"register/preflight"
+	"register/repository"
 	"register/service"
 )
 
@@ -36,25 +54,62 @@ func main() {
 }
 
 func runServer(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	policy := redact.NewPolicy(cfg.RedactPatterns...)
+
 	// Initialize logger
-	log := logger.NewZapLogger()
+	log := logger.NewZapLogger(policy)
 	defer log.Sync()
-	cfg := config.Load()
-	c := http.NewRestyClient(log)
-	svc := service.NewCDCRegistrationService(cfg, log, c)
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.TracingExporter, cfg.TracingServiceName, cfg.TracingSampleRatio)
+	if err != nil {
+		log.Fatal("Failed to initialize tracing", logger.Error(err))
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Warn("Failed to flush traces", logger.Error(err))
+		}
+	}()
+	c := connect.NewClient(cfg.ConnectorUrl, http.NewRestyClient(log))
+
+	qb := db.NewQueryBuilder(cfg.DatabaseURL, log)
+	if err := qb.AutoMigrate(&models.Connector{}); err != nil {
+		log.Fatal("Failed to migrate connector registry", logger.Error(err))
+	}
+	repo := repository.NewConnectorRepository(qb)
+
+	checker := preflight.NewChecker(log)
+
+	store := newSecretStore(cfg, log)
+
+	tenants := loadTenants(cfg, log)
+
+	svc := service.NewCDCRegistrationService(cfg, log, c, repo, checker, store, policy, tenants)
 	log.Info("Starting CDC Registration Service")
 
-	h := handler.NewCDCHandler(svc, log)
+	if cfg.ReconcileInterval > 0 {
+		go service.NewReconciler(svc, cfg.ReconcileInterval, log).Start(context.Background())
+	}
 
-	r := http.NewGinServer(log)
-	api := r.Group("/api")
-	{
-		api.POST("/connector", h.RegisterConnector)
-		api.GET("connectors", h.ListConnectors)
-		api.GET("/connectors/:name/status", h.GetConnectorStatus)
-		api.DELETE("/connectors/:name", h.DeleteConnector)
+	webhooks := newWebhookDispatcher(cfg, log)
+	if webhooks != nil {
+		go webhooks.Start(context.Background())
+		go service.NewStatusWatcher(svc, webhooks, cfg.StatusWatchInterval, log).Start(context.Background())
+	}
+
+	if cfg.AutoHealInterval > 0 {
+		go service.NewHealer(svc, webhooks, cfg.AutoHealInterval, log).Start(context.Background())
 	}
 
+	if cfg.MetricsRefreshInterval > 0 {
+		go service.NewMetricsRefresher(svc, cfg.MetricsRefreshInterval, log).Start(context.Background())
+	}
+
+	if err := handler.RegisterValidators(); err != nil {
+		log.Fatal("Failed to register request validators", logger.Error(err))
+	}
+	r := newRouter(cfg, log, policy, svc, repo, webhooks, tenants)
+
 	log.Info("Starting CDC Registration Service")
 	for _, route := range r.Routes() {
 		log.Info("Registered route",
@@ -63,8 +118,175 @@ func runServer(cmd *cobra.Command, args []string) {
 			logger.String("handler", route.Handler),
 		)
 	}
-	err := r.Run(":" + port)
+	if err := serve(r, cfg); err != nil {
+		log.Error("Server stopped", logger.Error(err))
+	}
+}
+
+// newRouter serves the API of svc behind the configured authentication,
+// authorization and tenancy middleware
+func newRouter(cfg *config.Config, log logger.Logger, policy *redact.Policy, svc service.CDCRegistrationService, repo repository.ConnectorRepository, webhooks webhook.Dispatcher, tenants *tenant.Directory) *gin.Engine {
+	h := handler.NewCDCHandler(svc, webhooks, policy, log)
+
+	authz := rbac.NewAuthorizer(loadRBACPolicy(cfg, log), topicPrefixResolver(repo), log)
+
+	r := http.NewGinServer(log)
+	middleware := append(newAuthMiddleware(cfg, log), tenant.Middleware(tenants, log))
+	api := r.Group("/api", middleware...)
+	{
+		api.POST("/connector", authz.Require(rbac.Write), h.RegisterConnector)
+		api.POST("/connectors/validate", authz.Require(rbac.Operate), h.ValidateConnector)
+		api.POST("/preflight", authz.Require(rbac.Operate), h.Preflight)
+		api.GET("connectors", authz.Require(rbac.Read), h.ListConnectors)
+		api.GET("/connectors/:name/status", authz.Require(rbac.Read), h.GetConnectorStatus)
+		api.PUT("/connectors/:name/config", authz.Require(rbac.Write), h.UpdateConnectorConfig)
+		api.POST("/connectors/:name/pause", authz.Require(rbac.Operate), h.PauseConnector)
+		api.POST("/connectors/:name/resume", authz.Require(rbac.Operate), h.ResumeConnector)
+		api.POST("/connectors/:name/restart", authz.Require(rbac.Operate), h.RestartConnector)
+		api.POST("/connectors/:name/tasks/:id/restart", authz.Require(rbac.Operate), h.RestartTask)
+		api.DELETE("/connectors/:name", authz.Require(rbac.Write), h.DeleteConnector)
+		api.GET("/reconciliation", authz.Require(rbac.Read), h.GetReconciliationReport)
+		api.GET("/tenant", authz.Require(rbac.Read), h.GetTenantUsage)
+		api.GET("/operations/:id", authz.Require(rbac.Read), h.GetOperation)
+		api.GET("/webhooks/deliveries", authz.Require(rbac.Read), h.GetWebhookDeliveries)
+		api.GET("/auto-heal", authz.Require(rbac.Read), h.GetHealingReport)
+	}
+	return r
+}
+
+// serve runs plain HTTP unless a TLS certificate is configured. With a client CA,
+// client certificates are verified and their common name is used for RBAC.
+func serve(r *gin.Engine, cfg *config.Config) error {
+	if cfg.TLSCertFile == "" {
+		return r.Run(":" + port)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLSClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in client CA file %s", cfg.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	server := &nethttp.Server{
+		Addr:      ":" + port,
+		Handler:   r,
+		TLSConfig: tlsConfig,
+	}
+	return server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+}
+
+func newSecretStore(cfg *config.Config, log logger.Logger) secrets.Store {
+	var (
+		store secrets.Store
+		err   error
+	)
+
+	switch cfg.SecretsBackend {
+	case "file":
+		store, err = secrets.NewFileStore(cfg.SecretsDir, cfg.SecretsMountPath)
+		if err != nil {
+			log.Fatal("Failed to initialize the file secrets backend, point SECRETS_DIR at a writable directory or choose another SECRETS_BACKEND",
+				logger.String("dir", cfg.SecretsDir),
+				logger.Error(err),
+			)
+		}
+	case "kubernetes":
+		store, err = secrets.NewKubernetesStore(cfg.SecretsNamespace, cfg.SecretsSecretName, cfg.SecretsMountPath)
+	case "none":
+		log.Warn("No secrets backend configured, database credentials are stored in connector configs")
+		return nil
+	default:
+		log.Fatal("Unknown secrets backend", logger.String("backend", cfg.SecretsBackend))
+	}
+
+	if err != nil {
+		log.Fatal("Failed to initialize secrets backend", logger.Error(err))
+	}
+	log.Info("Using secrets backend", logger.String("backend", cfg.SecretsBackend))
+	return store
+}
+
+// newAuthMiddleware returns no middleware when neither API keys nor a JWKS file are configured
+func newAuthMiddleware(cfg *config.Config, log logger.Logger) []gin.HandlerFunc {
+	var authenticators []auth.Authenticator
+
+	if len(cfg.APIKeys) > 0 {
+		authenticators = append(authenticators, auth.NewAPIKeyAuthenticator(cfg.APIKeys))
+	}
+	if cfg.JWKSFile != "" {
+		jwtAuth, err := auth.NewJWTAuthenticator(cfg.JWKSFile, cfg.JWTIssuer, cfg.JWTAudience)
+		if err != nil {
+			log.Fatal("Failed to initialize JWT authentication", logger.Error(err))
+		}
+		authenticators = append(authenticators, jwtAuth)
+	}
+
+	if len(authenticators) == 0 {
+		log.Warn("Authentication is disabled, configure API_KEYS or JWKS_FILE to protect the API")
+		return nil
+	}
+	return []gin.HandlerFunc{auth.Middleware(auth.Chain(authenticators...), log)}
+}
+
+// loadRBACPolicy returns nil, which disables authorization, without a policy file
+func loadRBACPolicy(cfg *config.Config, log logger.Logger) *rbac.Policy {
+	if cfg.RBACPolicyFile == "" {
+		log.Warn("Authorization is disabled, configure RBAC_POLICY_FILE to enforce roles")
+		return nil
+	}
+
+	policy, err := rbac.LoadPolicy(cfg.RBACPolicyFile)
 	if err != nil {
-		return
+		log.Fatal("Failed to load RBAC policy", logger.Error(err))
+	}
+	return policy
+}
+
+// loadTenants returns nil, which disables multi-tenancy, without a tenants file
+func loadTenants(cfg *config.Config, log logger.Logger) *tenant.Directory {
+	if cfg.TenantsFile == "" {
+		return nil
+	}
+
+	tenants, err := tenant.LoadDirectory(cfg.TenantsFile)
+	if err != nil {
+		log.Fatal("Failed to load tenants", logger.Error(err))
+	}
+	log.Info("Multi-tenancy enabled", logger.Int("tenants", len(tenants.Tenants)))
+	return tenants
+}
+
+// newWebhookDispatcher returns nil, which disables the status watcher, without a webhooks file
+func newWebhookDispatcher(cfg *config.Config, log logger.Logger) webhook.Dispatcher {
+	if cfg.WebhooksFile == "" {
+		return nil
+	}
+
+	webhookConfig, err := webhook.LoadConfig(cfg.WebhooksFile)
+	if err != nil {
+		log.Fatal("Failed to load webhooks", logger.Error(err))
+	}
+	log.Info("Webhook notifications enabled", logger.Int("subscribers", len(webhookConfig.Subscribers)))
+	return webhook.NewDispatcher(*webhookConfig, log)
+}
+
+func topicPrefixResolver(repo repository.ConnectorRepository) rbac.TopicPrefixResolver {
+	return func(ctx context.Context, connectorName string) (string, error) {
+		record, err := repo.FindByName(ctx, connectorName)
+		if err != nil {
+			return "", err
+		}
+		if record == nil {
+			return "", errors.New("connector is not in the registry")
+		}
+		return record.TopicPrefix, nil
 	}
 }
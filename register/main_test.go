@@ -0,0 +1,1146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"register/config"
+	"register/handler"
+	"register/models"
+	"register/pkg/apierror"
+	"register/pkg/connect"
+	"register/pkg/connect/connecttest"
+	"register/pkg/http"
+	"register/pkg/logger"
+	"register/pkg/redact"
+	"register/pkg/tenant"
+	"register/pkg/webhook"
+	"register/preflight"
+	"register/service"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testEnv is the service wired like runServer does, against a fake Kafka
+// Connect and an in-memory registry
+type testEnv struct {
+	router     *gin.Engine
+	service    service.CDCRegistrationService
+	connect    *connecttest.Server
+	repo       *memoryRepository
+	secretsDir string
+	apiKey     string // sent by do when set
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	fake := connecttest.NewServer()
+	t.Cleanup(fake.Close)
+
+	t.Setenv("KAFKA_CONNECT_URL", fake.URL)
+	t.Setenv("KAFKA_CONNECT_TIMEOUT", "2s")
+	t.Setenv("KAFKA_CONNECT_RETRY_WAIT", "10ms")
+	t.Setenv("REGISTRATION_POLL_INTERVAL", "10ms")
+	t.Setenv("REGISTRATION_TIMEOUT", "2s")
+	if _, ok := os.LookupEnv("SECRETS_BACKEND"); !ok {
+		t.Setenv("SECRETS_BACKEND", "file")
+	}
+	if _, ok := os.LookupEnv("ALLOW_SKIP_PREFLIGHT"); !ok {
+		t.Setenv("ALLOW_SKIP_PREFLIGHT", "true") // registerRequest skips the checks against the fake databases
+	}
+	t.Setenv("SECRETS_DIR", t.TempDir())
+	t.Setenv("SECRETS_MOUNT_PATH", "/secrets")
+
+	cfg := config.Load()
+	policy := redact.NewPolicy(cfg.RedactPatterns...)
+	log := logger.NewZapLogger(policy)
+
+	repo := newMemoryRepository()
+	c := connect.NewClient(cfg.ConnectorUrl, http.NewRestyClient(log))
+	svc := service.NewCDCRegistrationService(cfg, log, c, repo, preflight.NewChecker(log), newSecretStore(cfg, log), policy, nil)
+
+	if err := handler.RegisterValidators(); err != nil {
+		t.Fatalf("failed to register validators: %v", err)
+	}
+	return &testEnv{
+		router:     newRouter(cfg, log, policy, svc, repo, nil, nil),
+		service:    svc,
+		connect:    fake,
+		repo:       repo,
+		secretsDir: cfg.SecretsDir,
+	}
+}
+
+// do sends a request to the router and decodes the JSON response into result, if not nil
+func (e *testEnv) do(t *testing.T, method, path string, body, result interface{}) int {
+	t.Helper()
+	return e.doAs(t, e.apiKey, method, path, body, result)
+}
+
+// doAs is do authenticated with apiKey, if set
+func (e *testEnv) doAs(t *testing.T, apiKey, method, path string, body, result interface{}) int {
+	t.Helper()
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			t.Fatalf("failed to encode request: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	w := httptest.NewRecorder()
+	e.router.ServeHTTP(w, req)
+
+	if result != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), result); err != nil {
+			t.Fatalf("%s %s: failed to decode response %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w.Code
+}
+
+// register registers a Postgres connector and waits for its registration to finish
+func (e *testEnv) register(t *testing.T, name string) *models.Operation {
+	t.Helper()
+
+	var created models.ConnectorResponse
+	if status := e.do(t, "POST", "/api/connector", registerRequest(name), &created); status != nethttp.StatusAccepted {
+		t.Fatalf("register %s: got status %d, want %d", name, status, nethttp.StatusAccepted)
+	}
+	return e.waitForOperation(t, created.OperationID)
+}
+
+func (e *testEnv) waitForOperation(t *testing.T, id string) *models.Operation {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		var op models.Operation
+		if status := e.do(t, "GET", "/api/operations/"+id, nil, &op); status != nethttp.StatusOK {
+			t.Fatalf("get operation %s: got status %d", id, status)
+		}
+		if op.Done() {
+			return &op
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("operation %s did not finish, last state %s: %s", id, op.State, op.Progress)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func registerRequest(name string) models.RegisterConnectorRequest {
+	return models.RegisterConnectorRequest{
+		ConnectorName: name,
+		DatabaseType:  models.POSTGRES,
+		DatabaseHost:  "orders-db",
+		DatabasePort:  5432,
+		DatabaseName:  "orders",
+		Username:      "debezium",
+		Password:      "s3cret",
+		TopicPrefix:   name,
+		Tables:        []string{"public.orders"},
+		SkipPreflight: true,
+	}
+}
+
+func TestRegisterListStatusDelete(t *testing.T) {
+	env := newTestEnv(t)
+
+	op := env.register(t, "orders")
+	if op.State != models.OperationSucceeded {
+		t.Fatalf("registration finished %s (%s), want %s", op.State, op.Error, models.OperationSucceeded)
+	}
+
+	config := env.connect.ConnectorConfig("orders")
+	if config == nil {
+		t.Fatal("connector was not created in Kafka Connect")
+	}
+	for key, value := range config {
+		if value == "s3cret" {
+			t.Errorf("password reached Kafka Connect in %s", key)
+		}
+	}
+
+	var list models.ListConnectorsResponse
+	if status := env.do(t, "GET", "/api/connectors", nil, &list); status != nethttp.StatusOK {
+		t.Fatalf("list: got status %d", status)
+	}
+	if len(list.Connectors) != 1 || list.Connectors[0].Name != "orders" || !list.Connectors[0].Registered {
+		t.Fatalf("list: got %+v, want the registered orders connector", list.Connectors)
+	}
+
+	var status models.ConnectorStatus
+	if code := env.do(t, "GET", "/api/connectors/orders/status", nil, &status); code != nethttp.StatusOK {
+		t.Fatalf("status: got status %d", code)
+	}
+	if status.Connector.State != connect.StateRunning || len(status.Tasks) != 1 || status.Tasks[0].State != connect.StateRunning {
+		t.Fatalf("status: got %+v, want a running connector with one running task", status)
+	}
+
+	if code := env.do(t, "DELETE", "/api/connectors/orders", nil, nil); code != nethttp.StatusOK {
+		t.Fatalf("delete: got status %d", code)
+	}
+	if names := env.connect.ConnectorNames(); len(names) != 0 {
+		t.Fatalf("delete: Kafka Connect still has %v", names)
+	}
+	if record, _ := env.repo.FindByName(context.Background(), "orders"); record == nil || record.Status != models.ConnectorStatusDeleted {
+		t.Fatalf("delete: registry record is %+v, want it marked deleted", record)
+	}
+
+	list = models.ListConnectorsResponse{}
+	env.do(t, "GET", "/api/connectors", nil, &list)
+	if len(list.Connectors) != 0 {
+		t.Fatalf("list after delete: got %+v, want none", list.Connectors)
+	}
+	if code := env.do(t, "GET", "/api/connectors/orders/status", nil, nil); code != nethttp.StatusNotFound {
+		t.Fatalf("status after delete: got status %d, want %d", code, nethttp.StatusNotFound)
+	}
+}
+
+func TestReconcileSkipsConnectorsBeingDeleted(t *testing.T) {
+	t.Setenv("KAFKA_CONNECT_DELETE_RETRIES", "0")
+	env := newTestEnv(t)
+	env.register(t, "orders")
+
+	// Kafka Connect removes the connector but the response never arrives
+	env.connect.InjectFault(connecttest.Fault{Method: "DELETE", Path: "/connectors/orders", Status: nethttp.StatusInternalServerError, Message: "Request timed out"})
+	if code := env.do(t, "DELETE", "/api/connectors/orders", nil, nil); code != nethttp.StatusBadGateway {
+		t.Fatalf("delete: got status %d, want %d", code, nethttp.StatusBadGateway)
+	}
+	if record, _ := env.repo.FindByName(context.Background(), "orders"); record == nil || record.Status != models.ConnectorStatusDeleting {
+		t.Fatalf("failed delete: registry record is %+v, want it marked deleting", record)
+	}
+	env.connect.ClearFaults()
+	req, _ := nethttp.NewRequest("DELETE", env.connect.URL+"/connectors/orders", nil)
+	if _, err := nethttp.DefaultClient.Do(req); err != nil {
+		t.Fatalf("failed to delete connector from Kafka Connect: %v", err)
+	}
+
+	report, err := env.service.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if len(report.Recreated) != 0 || len(env.connect.ConnectorNames()) != 0 {
+		t.Fatalf("reconcile re-created %v, want the connector being deleted left alone", report.Recreated)
+	}
+
+	if code := env.do(t, "DELETE", "/api/connectors/orders", nil, nil); code != nethttp.StatusOK {
+		t.Fatalf("retried delete: got status %d", code)
+	}
+	if record, _ := env.repo.FindByName(context.Background(), "orders"); record.Status != models.ConnectorStatusDeleted {
+		t.Fatalf("retried delete: registry record is %+v, want it marked deleted", record)
+	}
+}
+
+func TestMongoDBRegistryRecordAndUpdate(t *testing.T) {
+	env := newTestEnv(t)
+
+	req := models.RegisterConnectorRequest{
+		ConnectorName:    "events",
+		DatabaseType:     models.MONGODB,
+		DatabaseName:     "shop",
+		Username:         "debezium",
+		Password:         "s3cret",
+		ConnectionString: "mongodb://mongo-0:27017,mongo-1:27017/?replicaSet=rs0",
+		TopicPrefix:      "events",
+		Tables:           []string{"orders"},
+		SkipPreflight:    true,
+	}
+	var created models.ConnectorResponse
+	if code := env.do(t, "POST", "/api/connector", req, &created); code != nethttp.StatusAccepted {
+		t.Fatalf("register: got status %d", code)
+	}
+	env.waitForOperation(t, created.OperationID)
+
+	record, _ := env.repo.FindByName(context.Background(), "events")
+	if record.DatabaseHost != "mongo-0:27017,mongo-1:27017" {
+		t.Fatalf("registry host is %q, want the hosts of the connection string", record.DatabaseHost)
+	}
+
+	// Records without a username fall back to the one Kafka Connect has
+	record.Username = ""
+	env.repo.Save(context.Background(), record)
+	update := models.UpdateConnectorRequest{Tables: []string{"orders", "customers"}}
+	if code := env.do(t, "PUT", "/api/connectors/events/config", update, nil); code != nethttp.StatusOK {
+		t.Fatalf("update: got status %d", code)
+	}
+	config := env.connect.ConnectorConfig("events")
+	if config["mongodb.user"] != "debezium" || config["collection.include.list"] != "shop.orders,shop.customers" {
+		t.Fatalf("update: got config %v, want the user kept and both collections", config)
+	}
+	if record, _ := env.repo.FindByName(context.Background(), "events"); record.DatabaseHost != "mongo-0:27017,mongo-1:27017" {
+		t.Fatalf("update: registry host is %q", record.DatabaseHost)
+	}
+}
+
+func TestDriftPersistsUntilReconciled(t *testing.T) {
+	env := newTestEnv(t)
+	env.register(t, "orders")
+	registered := env.connect.ConnectorConfig("orders")
+
+	putConfig := func(config map[string]string) {
+		t.Helper()
+		body, _ := json.Marshal(config)
+		req, _ := nethttp.NewRequest("PUT", env.connect.URL+"/connectors/orders/config", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to edit connector config: %v", err)
+		}
+		resp.Body.Close()
+	}
+	status := func() string {
+		t.Helper()
+		record, _ := env.repo.FindByName(context.Background(), "orders")
+		return record.Status
+	}
+
+	edited := make(map[string]string, len(registered))
+	for k, v := range registered {
+		edited[k] = v
+	}
+	edited["snapshot.mode"] = "never"
+	putConfig(edited)
+
+	if _, err := env.service.Reconcile(context.Background()); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if got := status(); got != models.ConnectorStatusDrifted {
+		t.Fatalf("after reconcile: got status %s, want %s", got, models.ConnectorStatusDrifted)
+	}
+
+	// Status reads, as the watcher and metrics refresher do, keep the drift flag
+	if code := env.do(t, "GET", "/api/connectors/orders/status", nil, nil); code != nethttp.StatusOK {
+		t.Fatalf("status: got %d", code)
+	}
+	if got := status(); got != models.ConnectorStatusDrifted {
+		t.Fatalf("after a status read: got status %s, want %s", got, models.ConnectorStatusDrifted)
+	}
+
+	putConfig(registered)
+	if _, err := env.service.Reconcile(context.Background()); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if got := status(); got != connect.StateRunning {
+		t.Fatalf("after the config was restored: got status %s, want %s", got, connect.StateRunning)
+	}
+}
+
+func TestRegisterTwiceConflicts(t *testing.T) {
+	env := newTestEnv(t)
+	env.register(t, "orders")
+
+	if code := env.do(t, "POST", "/api/connector", registerRequest("orders"), nil); code != nethttp.StatusConflict {
+		t.Fatalf("second register: got status %d, want %d", code, nethttp.StatusConflict)
+	}
+}
+
+func TestAPIRejectsUnauthenticatedCalls(t *testing.T) {
+	t.Setenv("API_KEYS", "ci:ci-key")
+	env := newTestEnv(t)
+
+	var body apierror.Response
+	if code := env.do(t, "GET", "/api/connectors", nil, &body); code != nethttp.StatusUnauthorized {
+		t.Fatalf("without credentials: got status %d, want %d", code, nethttp.StatusUnauthorized)
+	}
+	if body.Error.Code != apierror.CodeUnauthorized {
+		t.Fatalf("without credentials: got error %+v", body.Error)
+	}
+	if code := env.doAs(t, "wrong-key", "POST", "/api/connector", registerRequest("orders"), nil); code != nethttp.StatusUnauthorized {
+		t.Fatalf("with an unknown key: got status %d, want %d", code, nethttp.StatusUnauthorized)
+	}
+	if calls := env.connect.Calls("POST", "/connectors"); calls != 0 {
+		t.Fatalf("Kafka Connect got %d create calls, want none", calls)
+	}
+	if code := env.doAs(t, "ci-key", "GET", "/api/connectors", nil, nil); code != nethttp.StatusOK {
+		t.Fatalf("with a key: got status %d, want %d", code, nethttp.StatusOK)
+	}
+	if code := env.do(t, "GET", "/health", nil, nil); code != nethttp.StatusOK {
+		t.Fatalf("health: got status %d, want %d", code, nethttp.StatusOK)
+	}
+}
+
+func TestWritersCannotSkipPreflightByDefault(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "rbac.yaml")
+	if err := os.WriteFile(policyFile, []byte("bindings:\n  - subject: sub:ci\n    role: admin\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RBAC_POLICY_FILE", policyFile)
+	t.Setenv("API_KEYS", "ci:ci-key")
+	t.Setenv("ALLOW_SKIP_PREFLIGHT", "false")
+	env := newTestEnv(t)
+
+	var body apierror.Response
+	if code := env.doAs(t, "ci-key", "POST", "/api/connector", registerRequest("orders"), &body); code != nethttp.StatusForbidden {
+		t.Fatalf("register with skip_preflight: got status %d, want %d", code, nethttp.StatusForbidden)
+	}
+	if body.Error.Code != apierror.CodeForbidden {
+		t.Fatalf("got error code %s, want %s", body.Error.Code, apierror.CodeForbidden)
+	}
+	if env.connect.ConnectorConfig("orders") != nil {
+		t.Fatal("connector was created without its pre-flight checks")
+	}
+}
+
+func TestTopicPrefixScopedRoles(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "rbac.yaml")
+	policy := `
+bindings:
+  - subject: sub:platform
+    role: admin
+  - subject: sub:team-a
+    role: admin
+    topic_prefixes: ["team-a*"]
+`
+	if err := os.WriteFile(policyFile, []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RBAC_POLICY_FILE", policyFile)
+	t.Setenv("API_KEYS", "platform:platform-key,team-a:team-a-key")
+	env := newTestEnv(t)
+	env.apiKey = "platform-key"
+
+	var created models.ConnectorResponse
+	if code := env.doAs(t, "platform-key", "POST", "/api/connector", registerRequest("orders"), &created); code != nethttp.StatusAccepted {
+		t.Fatalf("platform register: got status %d, want %d", code, nethttp.StatusAccepted)
+	}
+	platformOp := created.OperationID
+	if code := env.doAs(t, "team-a-key", "POST", "/api/connector", registerRequest("team-a-orders"), &created); code != nethttp.StatusAccepted {
+		t.Fatalf("team-a register: got status %d, want %d", code, nethttp.StatusAccepted)
+	}
+	env.waitForOperation(t, platformOp)
+	env.waitForOperation(t, created.OperationID)
+
+	req := registerRequest("payments")
+	req.TopicPrefix = "payments"
+	if code := env.doAs(t, "team-a-key", "POST", "/api/connector", req, nil); code != nethttp.StatusForbidden {
+		t.Fatalf("register outside the scope: got status %d, want %d", code, nethttp.StatusForbidden)
+	}
+
+	var list models.ListConnectorsResponse
+	if code := env.doAs(t, "team-a-key", "GET", "/api/connectors", nil, &list); code != nethttp.StatusOK {
+		t.Fatalf("list: got status %d", code)
+	}
+	if len(list.Connectors) != 1 || list.Connectors[0].Name != "team-a-orders" {
+		t.Fatalf("list: got %+v, want only team-a-orders", list.Connectors)
+	}
+
+	if code := env.doAs(t, "team-a-key", "GET", "/api/operations/"+platformOp, nil, nil); code != nethttp.StatusNotFound {
+		t.Fatalf("operation outside the scope: got status %d, want %d", code, nethttp.StatusNotFound)
+	}
+
+	if _, err := env.service.Reconcile(context.Background()); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	var report models.ReconciliationReport
+	if code := env.doAs(t, "team-a-key", "GET", "/api/reconciliation", nil, &report); code != nethttp.StatusOK {
+		t.Fatalf("reconciliation: got status %d", code)
+	}
+	if len(report.InSync) != 1 || report.InSync[0] != "team-a-orders" {
+		t.Fatalf("reconciliation: got in sync %v, want only team-a-orders", report.InSync)
+	}
+}
+
+func TestRegisterRejectsInvalidRequest(t *testing.T) {
+	env := newTestEnv(t)
+
+	req := registerRequest("orders")
+	req.Tables = nil
+	if code := env.do(t, "POST", "/api/connector", req, nil); code != nethttp.StatusBadRequest {
+		t.Fatalf("got status %d, want %d", code, nethttp.StatusBadRequest)
+	}
+	if calls := env.connect.Calls("POST", "/connectors"); calls != 0 {
+		t.Fatalf("Kafka Connect got %d create calls, want none", calls)
+	}
+}
+
+func TestRegisterRejectsUnsafeConnectorNames(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, name := range []string{"../../etc/x", "..", "orders/x", "orders x"} {
+		req := registerRequest("orders")
+		req.ConnectorName = name
+		var body apierror.Response
+		if code := env.do(t, "POST", "/api/connector", req, &body); code != nethttp.StatusBadRequest {
+			t.Fatalf("%q: got status %d, want %d", name, code, nethttp.StatusBadRequest)
+		}
+		if !strings.Contains(body.Error.Message, "invalid connector_name") {
+			t.Fatalf("%q: got error %q", name, body.Error.Message)
+		}
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(env.secretsDir)); len(entries) != 1 {
+		t.Fatalf("secrets were written next to the secrets directory: %v", entries)
+	}
+}
+
+func TestTransformsCannotOverrideTheConnectorConfig(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, key := range []string{"topic.prefix", "tasks.max", "database.password", "transformsX"} {
+		req := registerRequest("orders")
+		req.Transforms = map[string]string{"transforms": "route", "transforms.route.type": "org.apache.kafka.connect.transforms.RegexRouter", key: "other"}
+		var body apierror.Response
+		if code := env.do(t, "POST", "/api/connector", req, &body); code != nethttp.StatusBadRequest {
+			t.Fatalf("%s: got status %d, want %d", key, code, nethttp.StatusBadRequest)
+		}
+		if !strings.Contains(body.Error.Message, "invalid transforms key") {
+			t.Fatalf("%s: got error %q", key, body.Error.Message)
+		}
+	}
+	if calls := env.connect.Calls("POST", "/connectors"); calls != 0 {
+		t.Fatalf("Kafka Connect got %d create calls, want none", calls)
+	}
+
+	req := registerRequest("orders")
+	req.Transforms = map[string]string{
+		"transforms":                  "unwrap",
+		"transforms.unwrap.type":      "io.debezium.transforms.ExtractNewRecordState",
+		"transforms.unwrap.predicate": "isOrders",
+		"predicates":                  "isOrders",
+		"predicates.isOrders.type":    "org.apache.kafka.connect.transforms.predicates.TopicNameMatches",
+		"predicates.isOrders.pattern": "orders.*",
+	}
+	var created models.ConnectorResponse
+	if code := env.do(t, "POST", "/api/connector", req, &created); code != nethttp.StatusAccepted {
+		t.Fatalf("transforms and predicates: got status %d, want %d", code, nethttp.StatusAccepted)
+	}
+	env.waitForOperation(t, created.OperationID)
+
+	update := models.UpdateConnectorRequest{Transforms: map[string]string{"topic.prefix": "other-team.orders"}}
+	if code := env.do(t, "PUT", "/api/connectors/orders/config", update, nil); code != nethttp.StatusBadRequest {
+		t.Fatalf("update: got status %d, want %d", code, nethttp.StatusBadRequest)
+	}
+	if config := env.connect.ConnectorConfig("orders"); config["topic.prefix"] != "orders" {
+		t.Fatalf("topic.prefix: got %q, want orders", config["topic.prefix"])
+	}
+}
+
+func TestRegisterRemovesSecretsWhenCreateIsRejected(t *testing.T) {
+	env := newTestEnv(t)
+	env.connect.InjectFault(connecttest.Fault{Method: "POST", Path: "/connectors", Status: nethttp.StatusBadRequest, Message: "Connector configuration is invalid"})
+
+	if code := env.do(t, "POST", "/api/connector", registerRequest("orders"), nil); code != nethttp.StatusBadRequest {
+		t.Fatalf("got status %d, want %d", code, nethttp.StatusBadRequest)
+	}
+	if entries, _ := os.ReadDir(env.secretsDir); len(entries) != 0 {
+		t.Fatalf("secrets of the rejected connector were kept: %v", entries)
+	}
+}
+
+func TestRegisterKeepsSecretsOfExistingConnectors(t *testing.T) {
+	t.Run("missing from the registry", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.register(t, "orders")
+		env.repo.forget("orders")
+
+		req := registerRequest("orders")
+		req.Password = "other"
+		if code := env.do(t, "POST", "/api/connector", req, nil); code != nethttp.StatusConflict {
+			t.Fatalf("got status %d, want %d", code, nethttp.StatusConflict)
+		}
+		data, err := os.ReadFile(filepath.Join(env.secretsDir, "orders.properties"))
+		if err != nil || !strings.Contains(string(data), "s3cret") || strings.Contains(string(data), "other") {
+			t.Fatalf("secrets of the running connector changed: %q, %v", data, err)
+		}
+	})
+
+	t.Run("created concurrently", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.connect.InjectFault(connecttest.Fault{Method: "POST", Path: "/connectors", Status: nethttp.StatusConflict, Message: "Connector orders already exists"})
+
+		if code := env.do(t, "POST", "/api/connector", registerRequest("orders"), nil); code != nethttp.StatusConflict {
+			t.Fatalf("got status %d, want %d", code, nethttp.StatusConflict)
+		}
+		if _, err := os.Stat(filepath.Join(env.secretsDir, "orders.properties")); err != nil {
+			t.Fatalf("secrets were removed after a conflict: %v", err)
+		}
+	})
+}
+
+func TestRegistryNeverStoresPlaintextCredentials(t *testing.T) {
+	t.Setenv("SECRETS_BACKEND", "none")
+	env := newTestEnv(t)
+	env.register(t, "orders")
+
+	if config := env.connect.ConnectorConfig("orders"); config["database.password"] != "s3cret" {
+		t.Fatalf("without a secrets backend Kafka Connect got password %q", config["database.password"])
+	}
+	record, _ := env.repo.FindByName(context.Background(), "orders")
+	for key, value := range record.Config {
+		if value == "s3cret" {
+			t.Fatalf("registry stores the password in %s", key)
+		}
+	}
+
+	// The masked password is not drift
+	report, err := env.service.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if len(report.InSync) != 1 || len(report.Drifted) != 0 {
+		t.Fatalf("got report %+v, want orders in sync", report)
+	}
+}
+
+func TestResponsesNeverCarrySecrets(t *testing.T) {
+	t.Setenv("SECRETS_BACKEND", "none")
+	t.Setenv("KAFKA_CONNECT_READ_RETRIES", "0")
+	env := newTestEnv(t)
+	const secret = "s3cret"
+
+	var created models.ConnectorResponse
+	if code := env.do(t, "POST", "/api/connector", registerRequest("orders"), &created); code != nethttp.StatusAccepted {
+		t.Fatalf("register: got status %d", code)
+	}
+	env.waitForOperation(t, created.OperationID)
+	env.connect.SetTaskState("orders", 0, connect.StateFailed, "org.postgresql.util.PSQLException: FATAL: password=s3cret rejected")
+	env.connect.InjectFault(connecttest.Fault{Method: "GET", Path: "/connectors/orders/config", Status: nethttp.StatusInternalServerError, Message: "Failed to read config database.password=s3cret"})
+
+	tests := []struct {
+		name         string
+		method, path string
+		body         interface{}
+	}{
+		{name: "register", method: "POST", path: "/api/connector", body: registerRequest("payments")},
+		{name: "status trace", method: "GET", path: "/api/connectors/orders/status"},
+		{name: "update diff", method: "PUT", path: "/api/connectors/payments/config", body: models.UpdateConnectorRequest{Password: "n3w-s3cret"}},
+		{name: "error message", method: "PUT", path: "/api/connectors/orders/config", body: models.UpdateConnectorRequest{DatabaseHost: "orders-db-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body json.RawMessage
+			env.do(t, tt.method, tt.path, tt.body, &body)
+			if strings.Contains(string(body), secret) {
+				t.Fatalf("response contains the secret: %s", body)
+			}
+			if !strings.Contains(string(body), redact.Mask) {
+				t.Fatalf("response %s has nothing masked, the test does not reach a secret", body)
+			}
+		})
+	}
+}
+
+func TestRegisterReportsValidationErrors(t *testing.T) {
+	env := newTestEnv(t)
+	env.connect.SetValidationErrors("database.hostname", "Unable to connect: connection refused")
+
+	var body struct {
+		Error struct {
+			Code    string                  `json:"code"`
+			Details models.ValidationResult `json:"details"`
+		} `json:"error"`
+	}
+	if code := env.do(t, "POST", "/api/connector", registerRequest("orders"), &body); code != nethttp.StatusUnprocessableEntity {
+		t.Fatalf("got status %d, want %d", code, nethttp.StatusUnprocessableEntity)
+	}
+	if body.Error.Code != apierror.CodeValidationFailed {
+		t.Fatalf("got code %s, want %s", body.Error.Code, apierror.CodeValidationFailed)
+	}
+	if validation := body.Error.Details; len(validation.Errors) != 1 || validation.Errors[0].Field != "database.hostname" {
+		t.Fatalf("got validation %+v, want an error for database.hostname", validation)
+	}
+	if names := env.connect.ConnectorNames(); len(names) != 0 {
+		t.Fatalf("invalid connector was created: %v", names)
+	}
+}
+
+func TestRegisterRetriesWhileRebalancing(t *testing.T) {
+	env := newTestEnv(t)
+	env.connect.Rebalance(2)
+
+	// The create policy does not retry, the validate call before it does
+	op := env.register(t, "orders")
+	if op.State != models.OperationSucceeded {
+		t.Fatalf("registration finished %s (%s), want %s", op.State, op.Error, models.OperationSucceeded)
+	}
+	validate := "/connector-plugins/io.debezium.connector.postgresql.PostgreSqlConnector/config/validate"
+	if calls := env.connect.Calls("PUT", validate); calls != 3 {
+		t.Fatalf("got %d validate calls, want 3", calls)
+	}
+}
+
+func TestReadsRetryWhileRebalancing(t *testing.T) {
+	env := newTestEnv(t)
+	env.register(t, "orders")
+	env.connect.Rebalance(2)
+
+	if code := env.do(t, "GET", "/api/connectors/orders/status", nil, nil); code != nethttp.StatusOK {
+		t.Fatalf("status: got status %d, want %d", code, nethttp.StatusOK)
+	}
+}
+
+func TestFailedTasksFailRegistration(t *testing.T) {
+	env := newTestEnv(t)
+	trace := "org.postgresql.util.PSQLException: FATAL: password authentication failed for user \"debezium\""
+	env.connect.SetInitialTaskState(connect.StateFailed, trace)
+
+	op := env.register(t, "orders")
+	if op.State != models.OperationFailed {
+		t.Fatalf("registration finished %s, want %s", op.State, models.OperationFailed)
+	}
+	if op.TasksFailed != 1 || op.Status == nil || !strings.Contains(op.Status.Tasks[0].Trace, "password authentication failed") {
+		t.Fatalf("operation %+v does not report the failed task and its trace", op)
+	}
+}
+
+func TestRestartFailedTask(t *testing.T) {
+	env := newTestEnv(t)
+	env.register(t, "orders")
+	env.connect.SetTaskState("orders", 0, connect.StateFailed, "java.lang.OutOfMemoryError: Java heap space")
+
+	var status models.ConnectorStatus
+	env.do(t, "GET", "/api/connectors/orders/status", nil, &status)
+	if status.Tasks[0].State != connect.StateFailed || status.Tasks[0].Trace == "" {
+		t.Fatalf("status: got task %+v, want it failed with a trace", status.Tasks[0])
+	}
+
+	status = models.ConnectorStatus{}
+	if code := env.do(t, "POST", "/api/connectors/orders/tasks/0/restart", nil, &status); code != nethttp.StatusOK {
+		t.Fatalf("restart: got status %d", code)
+	}
+	if status.Tasks[0].State != connect.StateRunning {
+		t.Fatalf("restart: got task %+v, want it running", status.Tasks[0])
+	}
+}
+
+func TestAutoHealGivesUpOnTasksThatKeepFailing(t *testing.T) {
+	t.Setenv("AUTO_HEAL_MAX_ATTEMPTS", "2")
+	t.Setenv("AUTO_HEAL_BACKOFF", "1ms")
+	t.Setenv("AUTO_HEAL_MAX_BACKOFF", "1ms")
+	t.Setenv("AUTO_HEAL_STABLE_AFTER", "1h")
+	env := newTestEnv(t)
+	env.register(t, "orders")
+
+	// Every restart brings the task back RUNNING for a moment before it fails again
+	for attempt := 1; attempt <= 2; attempt++ {
+		env.connect.SetTaskState("orders", 0, connect.StateFailed, "java.lang.OutOfMemoryError")
+		if exhausted := env.heal(t); len(exhausted) != 0 {
+			t.Fatalf("attempt %d: gave up on %+v, want a restart", attempt, exhausted)
+		}
+		time.Sleep(5 * time.Millisecond)
+		if exhausted := env.heal(t); len(exhausted) != 0 {
+			t.Fatalf("attempt %d: gave up on a running task", attempt)
+		}
+	}
+
+	env.connect.SetTaskState("orders", 0, connect.StateFailed, "java.lang.OutOfMemoryError")
+	exhausted := env.heal(t)
+	if len(exhausted) != 1 || len(exhausted[0].Attempts) != 2 {
+		t.Fatalf("got exhausted %+v, want the task given up after 2 attempts", exhausted)
+	}
+	if calls := env.connect.Calls("POST", "/connectors/orders/tasks/0/restart"); calls != 2 {
+		t.Fatalf("got %d task restarts, want 2", calls)
+	}
+}
+
+func (e *testEnv) heal(t *testing.T) []models.TaskHealing {
+	t.Helper()
+	exhausted, err := e.service.Heal(context.Background())
+	if err != nil {
+		t.Fatalf("heal: %v", err)
+	}
+	return exhausted
+}
+
+func TestWatcherNotifiesFailuresAcrossStatusErrors(t *testing.T) {
+	t.Setenv("KAFKA_CONNECT_READ_RETRIES", "0")
+	env := newTestEnv(t)
+	env.register(t, "orders")
+
+	events := make(chan webhook.Event, 10)
+	subscriber := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		var event webhook.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err == nil {
+			events <- event
+		}
+	}))
+	t.Cleanup(subscriber.Close)
+
+	log := logger.NewZapLogger(redact.NewPolicy())
+	webhooks := webhook.NewDispatcher(webhook.Config{Subscribers: []webhook.Subscriber{{URL: subscriber.URL, Secret: "shh"}}}, log)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go webhooks.Start(ctx)
+	statusReads := env.connect.Calls("GET", "/connectors/orders/status")
+	go service.NewStatusWatcher(env.service, webhooks, 10*time.Millisecond, log).Start(ctx)
+
+	// Let the watcher record the running connector, then fail its task while Kafka Connect blips
+	listPath := "/connectors"
+	for deadline := time.Now().Add(2 * time.Second); env.connect.Calls("GET", listPath) < 2; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("watcher did not poll the connector statuses")
+		}
+	}
+	env.connect.InjectFault(connecttest.Fault{Method: "GET", Path: listPath, Status: nethttp.StatusInternalServerError, Message: "Request timed out", Times: 2})
+	env.connect.SetTaskState("orders", 0, connect.StateFailed, "org.postgresql.util.PSQLException: Connection refused")
+
+	select {
+	case event := <-events:
+		if event.Type != webhook.TaskStateChanged || event.From != connect.StateRunning || event.To != connect.StateFailed {
+			t.Fatalf("got event %+v, want task RUNNING -> FAILED", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no webhook for the failed task")
+	}
+	if calls := env.connect.Calls("GET", "/connectors/orders/status") - statusReads; calls != 0 {
+		t.Fatalf("watcher read the connector status %d times, want it to use the connector list", calls)
+	}
+}
+
+func TestBackgroundStatusReadsOnlyWriteChanges(t *testing.T) {
+	env := newTestEnv(t)
+	env.register(t, "orders")
+	writes := env.repo.statusWrites()
+
+	for i := 0; i < 3; i++ {
+		if _, err := env.service.ConnectorStatuses(context.Background()); err != nil {
+			t.Fatalf("statuses: %v", err)
+		}
+	}
+	if got := env.repo.statusWrites() - writes; got != 0 {
+		t.Fatalf("unchanged statuses: got %d registry writes, want none", got)
+	}
+
+	env.connect.SetConnectorState("orders", connect.StatePaused, "")
+	statuses, err := env.service.ConnectorStatuses(context.Background())
+	if err != nil {
+		t.Fatalf("statuses: %v", err)
+	}
+	if statuses["orders"] == nil || statuses["orders"].Connector.State != connect.StatePaused {
+		t.Fatalf("got statuses %+v, want orders paused", statuses)
+	}
+	if got := env.repo.statusWrites() - writes; got != 1 {
+		t.Fatalf("changed status: got %d registry writes, want 1", got)
+	}
+	if record, _ := env.repo.FindByName(context.Background(), "orders"); record.Status != connect.StatePaused {
+		t.Fatalf("registry status: got %s, want %s", record.Status, connect.StatePaused)
+	}
+}
+
+func TestConcurrentRegistrationsRespectTheQuota(t *testing.T) {
+	env := newTestEnv(t)
+	cfg := config.Load()
+	log := logger.NewZapLogger(redact.NewPolicy())
+	tenants := &tenant.Directory{Tenants: []tenant.Tenant{{Name: "team-a", MaxConnectors: 1}}}
+	svc := service.NewCDCRegistrationService(cfg, log, connect.NewClient(cfg.ConnectorUrl, http.NewRestyClient(log)), env.repo, preflight.NewChecker(log), nil, redact.NewPolicy(), tenants)
+
+	const registrations = 5
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		created  int
+		rejected int
+	)
+	for i := 0; i < registrations; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := registerRequest(fmt.Sprintf("team-a.orders-%d", i))
+			req.Tenant = "team-a"
+			_, err := svc.RegisterConnector(context.Background(), req)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				created++
+			case errors.Is(err, service.ErrQuotaExceeded):
+				rejected++
+			default:
+				t.Errorf("register: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if created != 1 || rejected != registrations-1 {
+		t.Fatalf("got %d created and %d rejected, want 1 and %d", created, rejected, registrations-1)
+	}
+	if connectors, _ := env.repo.FindActiveByTenant(context.Background(), "team-a"); len(connectors) != 1 {
+		t.Fatalf("registry has %d connectors of team-a, want 1", len(connectors))
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	env := newTestEnv(t)
+	env.register(t, "orders")
+
+	var status models.ConnectorStatus
+	env.do(t, "POST", "/api/connectors/orders/pause", nil, &status)
+	if status.Connector.State != connect.StatePaused {
+		t.Fatalf("pause: got connector %s, want %s", status.Connector.State, connect.StatePaused)
+	}
+	env.do(t, "POST", "/api/connectors/orders/resume", nil, &status)
+	if status.Connector.State != connect.StateRunning {
+		t.Fatalf("resume: got connector %s, want %s", status.Connector.State, connect.StateRunning)
+	}
+}
+
+func TestKafkaConnectUnavailable(t *testing.T) {
+	t.Setenv("KAFKA_CONNECT_RETRIES", "1")
+	env := newTestEnv(t)
+	env.connect.InjectFault(connecttest.Fault{Method: "GET", Path: "/connectors", Status: nethttp.StatusServiceUnavailable, Message: "Kafka Connect is starting"})
+
+	var body apierror.Response
+	if code := env.do(t, "GET", "/api/connectors", nil, &body); code != nethttp.StatusServiceUnavailable {
+		t.Fatalf("got status %d, want %d", code, nethttp.StatusServiceUnavailable)
+	}
+	if body.Error.Code != apierror.CodeUpstreamUnavailable || body.Error.UpstreamStatus != nethttp.StatusServiceUnavailable {
+		t.Fatalf("got error %+v, want %s with upstream status 503", body.Error, apierror.CodeUpstreamUnavailable)
+	}
+	if calls := env.connect.Calls("GET", "/connectors"); calls != 2 {
+		t.Fatalf("got %d list calls, want 2", calls)
+	}
+}
+
+func TestKafkaConnectTimeout(t *testing.T) {
+	t.Setenv("KAFKA_CONNECT_READ_TIMEOUT", "50ms")
+	t.Setenv("KAFKA_CONNECT_READ_RETRIES", "0")
+	env := newTestEnv(t)
+	env.connect.SetLatency(time.Second)
+
+	start := time.Now()
+	var body apierror.Response
+	if code := env.do(t, "GET", "/api/connectors", nil, &body); code != nethttp.StatusServiceUnavailable {
+		t.Fatalf("got status %d, want %d", code, nethttp.StatusServiceUnavailable)
+	}
+	if body.Error.Code != apierror.CodeUpstreamUnavailable || body.Error.UpstreamStatus != 0 {
+		t.Fatalf("got error %+v, want %s without upstream status", body.Error, apierror.CodeUpstreamUnavailable)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("call took %s, the read timeout is 50ms", elapsed)
+	}
+}
+
+func TestKafkaConnectErrorsKeepTheirStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		fault    connecttest.Fault
+		method   string
+		path     string
+		body     interface{}
+		status   int
+		code     string
+		upstream int
+	}{
+		{
+			name:     "rejected config",
+			fault:    connecttest.Fault{Method: "POST", Path: "/connectors", Status: nethttp.StatusBadRequest, Message: "Connector configuration is invalid"},
+			method:   "POST",
+			path:     "/api/connector",
+			body:     registerRequest("orders"),
+			status:   nethttp.StatusBadRequest,
+			code:     apierror.CodeInvalidRequest,
+			upstream: nethttp.StatusBadRequest,
+		},
+		{
+			name:     "connector created concurrently",
+			fault:    connecttest.Fault{Method: "POST", Path: "/connectors", Status: nethttp.StatusConflict, Message: "Connector orders already exists"},
+			method:   "POST",
+			path:     "/api/connector",
+			body:     registerRequest("orders"),
+			status:   nethttp.StatusConflict,
+			code:     apierror.CodeConflict,
+			upstream: nethttp.StatusConflict,
+		},
+		{
+			name:     "unknown connector",
+			method:   "GET",
+			path:     "/api/connectors/missing/status",
+			status:   nethttp.StatusNotFound,
+			code:     apierror.CodeNotFound,
+			upstream: nethttp.StatusNotFound,
+		},
+		{
+			name:     "rebalance outlasts the retries",
+			fault:    connecttest.Fault{Status: nethttp.StatusConflict, Message: connecttest.RebalanceMessage},
+			method:   "GET",
+			path:     "/api/connectors",
+			status:   nethttp.StatusServiceUnavailable,
+			code:     apierror.CodeUpstreamUnavailable,
+			upstream: nethttp.StatusConflict,
+		},
+		{
+			name:     "worker error",
+			fault:    connecttest.Fault{Method: "GET", Path: "/connectors", Status: nethttp.StatusInternalServerError, Message: "Request timed out"},
+			method:   "GET",
+			path:     "/api/connectors",
+			status:   nethttp.StatusBadGateway,
+			code:     apierror.CodeUpstreamError,
+			upstream: nethttp.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KAFKA_CONNECT_RETRIES", "1")
+			env := newTestEnv(t)
+			if tt.fault.Status != 0 {
+				env.connect.InjectFault(tt.fault)
+			}
+
+			var body apierror.Response
+			if code := env.do(t, tt.method, tt.path, tt.body, &body); code != tt.status {
+				t.Fatalf("got status %d (%+v), want %d", code, body.Error, tt.status)
+			}
+			if body.Error.Code != tt.code || body.Error.UpstreamStatus != tt.upstream {
+				t.Fatalf("got error %+v, want code %s and upstream status %d", body.Error, tt.code, tt.upstream)
+			}
+			if body.Error.Message == "" || body.Error.RequestID == "" {
+				t.Fatalf("got error %+v, want a message and a request ID", body.Error)
+			}
+		})
+	}
+}
+
+func TestErrorsCarryTheRequestID(t *testing.T) {
+	env := newTestEnv(t)
+
+	req := httptest.NewRequest("GET", "/api/operations/unknown", nil)
+	req.Header.Set(apierror.RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+
+	var body apierror.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response %q: %v", w.Body.String(), err)
+	}
+	if w.Code != nethttp.StatusNotFound || body.Error.Code != apierror.CodeNotFound {
+		t.Fatalf("got status %d and error %+v, want %d %s", w.Code, body.Error, nethttp.StatusNotFound, apierror.CodeNotFound)
+	}
+	if body.Error.RequestID != "req-42" || w.Header().Get(apierror.RequestIDHeader) != "req-42" {
+		t.Fatalf("got request ID %q and header %q, want req-42", body.Error.RequestID, w.Header().Get(apierror.RequestIDHeader))
+	}
+}
+
+// memoryRepository is a repository.ConnectorRepository backed by a map
+type memoryRepository struct {
+	mu         sync.Mutex
+	connectors map[string]models.Connector
+	writes     int // UpdateStatus calls
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{connectors: make(map[string]models.Connector)}
+}
+
+func (r *memoryRepository) Save(ctx context.Context, connector *models.Connector) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.connectors[connector.ConnectorName]; ok {
+		connector.CreatedAt = existing.CreatedAt
+	} else {
+		connector.CreatedAt = time.Now()
+	}
+	connector.UpdatedAt = time.Now()
+	r.connectors[connector.ConnectorName] = *connector
+	return nil
+}
+
+// forget drops a record as if the registry lost it
+func (r *memoryRepository) forget(connectorName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.connectors, connectorName)
+}
+
+func (r *memoryRepository) FindByName(ctx context.Context, connectorName string) (*models.Connector, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	connector, ok := r.connectors[connectorName]
+	if !ok {
+		return nil, nil
+	}
+	return &connector, nil
+}
+
+func (r *memoryRepository) FindAll(ctx context.Context) ([]models.Connector, error) {
+	return r.find(func(models.Connector) bool { return true }), nil
+}
+
+func (r *memoryRepository) FindActiveByTenant(ctx context.Context, tenant string) ([]models.Connector, error) {
+	return r.find(func(c models.Connector) bool {
+		return c.Tenant == tenant && c.Status != models.ConnectorStatusDeleted
+	}), nil
+}
+
+func (r *memoryRepository) FindActiveByTopicPrefix(ctx context.Context, topicPrefix string) ([]models.Connector, error) {
+	return r.find(func(c models.Connector) bool {
+		return c.TopicPrefix == topicPrefix && c.Status != models.ConnectorStatusDeleted
+	}), nil
+}
+
+func (r *memoryRepository) UpdateStatus(ctx context.Context, connectorName string, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writes++
+	if connector, ok := r.connectors[connectorName]; ok && !connector.Removed() && connector.Status != models.ConnectorStatusDrifted {
+		connector.Status = status
+		r.connectors[connectorName] = connector
+	}
+	return nil
+}
+
+func (r *memoryRepository) statusWrites() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.writes
+}
+
+func (r *memoryRepository) UpdateReconciledStatus(ctx context.Context, connectorName string, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if connector, ok := r.connectors[connectorName]; ok && !connector.Removed() {
+		connector.Status = status
+		r.connectors[connectorName] = connector
+	}
+	return nil
+}
+
+func (r *memoryRepository) MarkDeleting(ctx context.Context, connectorName string) error {
+	return r.setStatus(connectorName, models.ConnectorStatusDeleting)
+}
+
+func (r *memoryRepository) MarkDeleted(ctx context.Context, connectorName string) error {
+	return r.setStatus(connectorName, models.ConnectorStatusDeleted)
+}
+
+func (r *memoryRepository) setStatus(connectorName string, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if connector, ok := r.connectors[connectorName]; ok {
+		connector.Status = status
+		r.connectors[connectorName] = connector
+	}
+	return nil
+}
+
+func (r *memoryRepository) find(match func(models.Connector) bool) []models.Connector {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var connectors []models.Connector
+	for _, connector := range r.connectors {
+		if match(connector) {
+			connectors = append(connectors, connector)
+		}
+	}
+	return connectors
+}
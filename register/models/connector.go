@@ -1,49 +1,149 @@
 package models
 
+import (
+	"regexp"
+	"strings"
+)
+
 type Database string
 
 const (
-	MYSQL    Database = "mysql"
-	POSTGRES Database = "postgres"
+	MYSQL     Database = "mysql"
+	POSTGRES  Database = "postgres"
+	SQLSERVER Database = "sqlserver"
+	MONGODB   Database = "mongodb"
+	ORACLE    Database = "oracle"
 )
 
+// connectorNamePattern keeps connector names safe to use in file names and URLs
+var connectorNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// ValidConnectorName reports whether name only has letters, digits, '.', '_'
+// and '-', and no ".."
+func ValidConnectorName(name string) bool {
+	return connectorNamePattern.MatchString(name) && !strings.Contains(name, "..")
+}
+
+// ValidTransformKey reports whether key configures single message transforms
+// or their predicates. Any other key would override the built connector config.
+func ValidTransformKey(key string) bool {
+	return key == "transforms" || key == "predicates" ||
+		strings.HasPrefix(key, "transforms.") || strings.HasPrefix(key, "predicates.")
+}
+
 // Request models
 type RegisterConnectorRequest struct {
-	ConnectorName string            `json:"connector_name" binding:"required"`
-	DatabaseType  Database          `json:"database_type" binding:"required"` // mysql, postgresql
-	DatabaseHost  string            `json:"database_host" binding:"required"`
-	DatabasePort  int               `json:"database_port" binding:"required"`
-	DatabaseName  string            `json:"database_name" binding:"required"`
-	Username      string            `json:"username" binding:"required"`
-	Password      string            `json:"password" binding:"required"`
-	TopicPrefix   string            `json:"topic_prefix" binding:"required"`
-	Tables        []string          `json:"tables" binding:"required"`
-	SnapshotMode  string            `json:"snapshot_mode,omitempty"` // initial, never, when_needed
-	ServerID      int               `json:"server_id,omitempty"`     // for MySQL
-	Transforms    map[string]string `json:"transforms,omitempty"`    // custom transforms
+	ConnectorName          string            `json:"connector_name" binding:"required,connector_name"`
+	DatabaseType           Database          `json:"database_type" binding:"required,database_type"` // mysql, postgres, sqlserver, mongodb, oracle
+	DatabaseHost           string            `json:"database_host" binding:"required_unless=DatabaseType mongodb"`
+	DatabasePort           int               `json:"database_port" binding:"required_unless=DatabaseType mongodb"`
+	DatabaseName           string            `json:"database_name" binding:"required"`
+	Username               string            `json:"username" binding:"required_unless=DatabaseType mongodb"`
+	Password               string            `json:"password" binding:"required_unless=DatabaseType mongodb"`
+	TopicPrefix            string            `json:"topic_prefix" binding:"required"`
+	Tables                 []string          `json:"tables" binding:"required"` // collections for MongoDB
+	SnapshotMode           string            `json:"snapshot_mode,omitempty"`   // initial, never, when_needed
+	ServerID               int               `json:"server_id,omitempty"`       // for MySQL
+	Schema                 string            `json:"schema,omitempty"`          // default schema of unqualified tables, for SQL Server and Oracle
+	Encrypt                bool              `json:"encrypt,omitempty"`         // for SQL Server
+	TrustServerCertificate bool              `json:"trust_server_certificate,omitempty"`
+	PDBName                string            `json:"pdb_name,omitempty"` // for Oracle multitenant databases
+	LogMiningStrategy      string            `json:"log_mining_strategy,omitempty" binding:"omitempty,oneof=online_catalog redo_log_catalog"`
+	Transforms             map[string]string `json:"transforms,omitempty" binding:"omitempty,dive,keys,transform_key,endkeys"` // transforms.* and predicates.* keys
+	SkipPreflight          bool              `json:"skip_preflight,omitempty"`                                                 // rejected unless the server sets ALLOW_SKIP_PREFLIGHT
+	CreatedBy              string            `json:"-"`                                                                        // set by the handler, recorded in the registry
+	Tenant                 string            `json:"-"`                                                                        // set by the handler when multi-tenancy is enabled
+
+	// MongoDB connects with a connection string instead of host and port
+	ConnectionString string `json:"connection_string,omitempty" binding:"required_if=DatabaseType mongodb"`
+	CaptureMode      string `json:"capture_mode,omitempty" binding:"omitempty,oneof=change_streams change_streams_update_full change_streams_with_pre_image change_streams_update_full_with_pre_image"`
+}
+
+// UpdateConnectorRequest is a partial RegisterConnectorRequest, omitted fields keep their registered value.
+// The connector name and database type cannot be changed in place.
+type UpdateConnectorRequest struct {
+	DatabaseHost           string            `json:"database_host,omitempty"`
+	DatabasePort           int               `json:"database_port,omitempty"`
+	DatabaseName           string            `json:"database_name,omitempty"`
+	Username               string            `json:"username,omitempty"`
+	Password               string            `json:"password,omitempty"`
+	TopicPrefix            string            `json:"topic_prefix,omitempty"`
+	Tables                 []string          `json:"tables,omitempty"`
+	SnapshotMode           string            `json:"snapshot_mode,omitempty"`
+	ServerID               int               `json:"server_id,omitempty"`
+	Schema                 string            `json:"schema,omitempty"`
+	Encrypt                *bool             `json:"encrypt,omitempty"`
+	TrustServerCertificate *bool             `json:"trust_server_certificate,omitempty"`
+	ConnectionString       string            `json:"connection_string,omitempty"`
+	CaptureMode            string            `json:"capture_mode,omitempty" binding:"omitempty,oneof=change_streams change_streams_update_full change_streams_with_pre_image change_streams_update_full_with_pre_image"`
+	PDBName                string            `json:"pdb_name,omitempty"`
+	LogMiningStrategy      string            `json:"log_mining_strategy,omitempty" binding:"omitempty,oneof=online_catalog redo_log_catalog"`
+	Transforms             map[string]string `json:"transforms,omitempty" binding:"omitempty,dive,keys,transform_key,endkeys"`
+}
+
+// RestartConnectorRequest maps to the query parameters of Kafka Connect's restart API
+type RestartConnectorRequest struct {
+	IncludeTasks bool `form:"includeTasks"`
+	OnlyFailed   bool `form:"onlyFailed"`
 }
 
 // Response models
 type ConnectorResponse struct {
 	ConnectorName string            `json:"connector_name"`
+	OperationID   string            `json:"operation_id"` // poll GET /api/operations/:id for the outcome
 	Status        string            `json:"status"`
 	Config        map[string]string `json:"config"`
 	CreatedAt     string            `json:"created_at"`
 }
 
+type ConfigChange struct {
+	Key    string `json:"key"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+type UpdateConnectorResponse struct {
+	ConnectorName string            `json:"connector_name"`
+	Before        map[string]string `json:"before"`
+	After         map[string]string `json:"after"`
+	Changes       []ConfigChange    `json:"changes"`
+	UpdatedAt     string            `json:"updated_at"`
+}
+
+// ConnectorStatus is the state of a connector and its tasks in Kafka Connect
 type ConnectorStatus struct {
-	Name      string `json:"name"`
-	Connector struct {
-		State    string `json:"state"`
-		WorkerID string `json:"worker_id"`
-	} `json:"connector"`
-	Tasks []struct {
-		ID       int    `json:"id"`
-		State    string `json:"state"`
-		WorkerID string `json:"worker_id"`
-	} `json:"tasks"`
+	Name      string         `json:"name"`
+	Connector ConnectorState `json:"connector"`
+	Tasks     []TaskState    `json:"tasks"`
+	Type      string         `json:"type,omitempty"`
+}
+
+type ConnectorState struct {
+	State    string `json:"state"`
+	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace,omitempty"`
+}
+
+type TaskState struct {
+	ID       int    `json:"id"`
+	State    string `json:"state"`
+	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace,omitempty"`
 }
 
 type ListConnectorsResponse struct {
-	Connectors []string `json:"connectors"`
+	Connectors []ConnectorSummary `json:"connectors"`
+}
+
+// ConnectorSummary is a Kafka Connect connector enriched with its registry record
+type ConnectorSummary struct {
+	Name         string `json:"name"`
+	Registered   bool   `json:"registered"`
+	Tenant       string `json:"tenant,omitempty"`
+	DatabaseType string `json:"database_type,omitempty"`
+	DatabaseHost string `json:"database_host,omitempty"`
+	TopicPrefix  string `json:"topic_prefix,omitempty"`
+	Status       string `json:"status,omitempty"`
+	CreatedBy    string `json:"created_by,omitempty"`
+	CreatedAt    string `json:"created_at,omitempty"`
 }
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Accepted spellings of each supported database type
+var databaseAliases = map[string]Database{
+	"mysql":      MYSQL,
+	"postgres":   POSTGRES,
+	"postgresql": POSTGRES,
+	"pg":         POSTGRES,
+	"pgsql":      POSTGRES,
+	"sqlserver":  SQLSERVER,
+	"mssql":      SQLSERVER,
+	"mongodb":    MONGODB,
+	"mongo":      MONGODB,
+	"oracle":     ORACLE,
+}
+
+// SupportedDatabases lists the canonical database types
+func SupportedDatabases() []Database {
+	return []Database{MYSQL, POSTGRES, SQLSERVER, MONGODB, ORACLE}
+}
+
+// ParseDatabase resolves a database type or one of its aliases to the canonical value
+func ParseDatabase(value string) (Database, bool) {
+	db, ok := databaseAliases[strings.ToLower(strings.TrimSpace(value))]
+	return db, ok
+}
+
+// DatabaseAliases returns the accepted aliases of a canonical database type
+func DatabaseAliases(db Database) []string {
+	var aliases []string
+	for alias, target := range databaseAliases {
+		if target == db && alias != string(db) {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+func (d Database) IsValid() bool {
+	_, ok := ParseDatabase(string(d))
+	return ok
+}
+
+// UnmarshalJSON normalizes aliases so the rest of the service only sees canonical values.
+// Unknown values are kept as sent and rejected by the database_type validator.
+func (d *Database) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if db, ok := ParseDatabase(value); ok {
+		*d = db
+		return nil
+	}
+	*d = Database(value)
+	return nil
+}
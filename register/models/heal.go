@@ -0,0 +1,24 @@
+package models
+
+// HealAttempt is one automatic restart of a failed task
+type HealAttempt struct {
+	Attempt     int    `json:"attempt"`
+	Trace       string `json:"trace,omitempty"` // the task's trace when it was restarted
+	RestartedAt string `json:"restarted_at"`
+	Error       string `json:"error,omitempty"`
+}
+
+// TaskHealing tracks a failed task until it runs again or the attempt budget is spent
+type TaskHealing struct {
+	ConnectorName string        `json:"connector_name"`
+	TaskID        int           `json:"task_id"`
+	FailedAt      string        `json:"failed_at"`
+	Attempts      []HealAttempt `json:"attempts"`
+	NextAttemptAt string        `json:"next_attempt_at,omitempty"`
+	GaveUp        bool          `json:"gave_up"`
+	Trace         string        `json:"trace,omitempty"` // latest trace
+}
+
+type HealingReport struct {
+	Tasks []TaskHealing `json:"tasks"`
+}
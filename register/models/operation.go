@@ -0,0 +1,31 @@
+package models
+
+const (
+	OperationPending   = "PENDING"
+	OperationSucceeded = "SUCCEEDED"
+	OperationFailed    = "FAILED"
+	OperationTimedOut  = "TIMED_OUT"
+)
+
+// Operation tracks a connector registration until its connector and tasks settle
+type Operation struct {
+	ID            string           `json:"id"`
+	Type          string           `json:"type"`
+	ConnectorName string           `json:"connector_name"`
+	Tenant        string           `json:"tenant,omitempty"`
+	State         string           `json:"state"`
+	Progress      string           `json:"progress"`
+	TasksTotal    int              `json:"tasks_total"`
+	TasksRunning  int              `json:"tasks_running"`
+	TasksFailed   int              `json:"tasks_failed"`
+	Status        *ConnectorStatus `json:"status,omitempty"` // last status seen, with task traces
+	Error         string           `json:"error,omitempty"`
+	CreatedAt     string           `json:"created_at"`
+	UpdatedAt     string           `json:"updated_at"`
+	FinishedAt    string           `json:"finished_at,omitempty"`
+}
+
+// Done reports whether the operation reached a final state
+func (o *Operation) Done() bool {
+	return o.State != OperationPending
+}
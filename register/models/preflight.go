@@ -0,0 +1,19 @@
+package models
+
+// PreflightCheck is a single readiness check against the source database
+type PreflightCheck struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Message  string `json:"message,omitempty"` // what to fix when the check fails
+}
+
+type PreflightReport struct {
+	DatabaseType Database         `json:"database_type"`
+	DatabaseHost string           `json:"database_host"`
+	DatabaseName string           `json:"database_name"`
+	Passed       bool             `json:"passed"`
+	Checks       []PreflightCheck `json:"checks"`
+	CheckedAt    string           `json:"checked_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// Reconciliation models
+type ConfigDifference struct {
+	Key     string `json:"key"`
+	Desired string `json:"desired,omitempty"`
+	Actual  string `json:"actual,omitempty"`
+}
+
+type ConnectorDrift struct {
+	ConnectorName string             `json:"connector_name"`
+	Differences   []ConfigDifference `json:"differences"`
+}
+
+type ReconciliationFailure struct {
+	ConnectorName string `json:"connector_name"`
+	Error         string `json:"error"`
+}
+
+type ReconciliationReport struct {
+	StartedAt  string                  `json:"started_at"`
+	FinishedAt string                  `json:"finished_at"`
+	InSync     []string                `json:"in_sync"`
+	Recreated  []string                `json:"recreated"`
+	Drifted    []ConnectorDrift        `json:"drifted"`
+	Orphans    []string                `json:"orphans"`
+	Failures   []ReconciliationFailure `json:"failures"`
+}
@@ -3,14 +3,67 @@ package models
 import "time"
 
 type Connector struct {
-	ID            uint      `gorm:"primaryKey" json:"id"`
-	ConnectorName string    `gorm:"uniqueIndex" json:"connector_name"`
-	DatabaseType  string    `json:"database_type"`
-	DatabaseHost  string    `json:"database_host"`
-	DatabasePort  int       `json:"database_port"`
-	DatabaseName  string    `json:"database_name"`
-	TopicPrefix   string    `json:"topic_prefix"`
-	Status        string    `json:"status"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID                     uint              `gorm:"primaryKey" json:"id"`
+	ConnectorName          string            `gorm:"uniqueIndex" json:"connector_name"`
+	Tenant                 string            `gorm:"index" json:"tenant,omitempty"`
+	DatabaseType           string            `json:"database_type"`
+	DatabaseHost           string            `json:"database_host"`
+	DatabasePort           int               `json:"database_port"`
+	DatabaseName           string            `json:"database_name"`
+	TopicPrefix            string            `json:"topic_prefix"`
+	Username               string            `json:"username"`
+	Tables                 []string          `gorm:"type:jsonb;serializer:json" json:"tables"`
+	SnapshotMode           string            `json:"snapshot_mode"`
+	ServerID               int               `json:"server_id"`
+	Schema                 string            `json:"schema"`
+	Encrypt                bool              `json:"encrypt"`
+	TrustServerCertificate bool              `json:"trust_server_certificate"`
+	CaptureMode            string            `json:"capture_mode"`
+	PDBName                string            `json:"pdb_name"`
+	LogMiningStrategy      string            `json:"log_mining_strategy"`
+	Transforms             map[string]string `gorm:"type:jsonb;serializer:json" json:"transforms"`
+	Status                 string            `json:"status"`
+	Config                 map[string]string `gorm:"type:jsonb;serializer:json" json:"config"` // desired Kafka Connect config
+	CreatedBy              string            `json:"created_by"`
+	CreatedAt              time.Time         `json:"created_at"`
+	UpdatedAt              time.Time         `json:"updated_at"`
+}
+
+// Request rebuilds the registration request the record was created from.
+// The password and MongoDB connection string are not columns of the record
+// and have to be filled in by the caller.
+func (c *Connector) Request() RegisterConnectorRequest {
+	return RegisterConnectorRequest{
+		ConnectorName:          c.ConnectorName,
+		DatabaseType:           Database(c.DatabaseType),
+		DatabaseHost:           c.DatabaseHost,
+		DatabasePort:           c.DatabasePort,
+		DatabaseName:           c.DatabaseName,
+		Username:               c.Username,
+		TopicPrefix:            c.TopicPrefix,
+		Tables:                 c.Tables,
+		SnapshotMode:           c.SnapshotMode,
+		ServerID:               c.ServerID,
+		Schema:                 c.Schema,
+		Encrypt:                c.Encrypt,
+		TrustServerCertificate: c.TrustServerCertificate,
+		CaptureMode:            c.CaptureMode,
+		PDBName:                c.PDBName,
+		LogMiningStrategy:      c.LogMiningStrategy,
+		Transforms:             c.Transforms,
+		CreatedBy:              c.CreatedBy,
+		Tenant:                 c.Tenant,
+	}
+}
+
+// Registry statuses set by the service itself rather than Kafka Connect
+const (
+	ConnectorStatusDeleting = "DELETING" // delete in progress, or failed part way and can be retried
+	ConnectorStatusDeleted  = "DELETED"
+	ConnectorStatusDrifted  = "DRIFTED"
+)
+
+// Removed reports whether the connector was deleted or is being deleted
+func (c *Connector) Removed() bool {
+	return c.Status == ConnectorStatusDeleting || c.Status == ConnectorStatusDeleted
 }
@@ -0,0 +1,9 @@
+package models
+
+type TenantUsage struct {
+	Tenant        string `json:"tenant"`
+	Connectors    int    `json:"connectors"`
+	MaxConnectors int    `json:"max_connectors,omitempty"`
+	Tasks         int    `json:"tasks"`
+	MaxTasks      int    `json:"max_tasks,omitempty"`
+}
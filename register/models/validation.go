@@ -0,0 +1,14 @@
+package models
+
+type FieldValidationError struct {
+	Field  string   `json:"field"`
+	Errors []string `json:"errors"`
+}
+
+type ValidationResult struct {
+	ConnectorName  string                 `json:"connector_name"`
+	ConnectorClass string                 `json:"connector_class"`
+	Valid          bool                   `json:"valid"`
+	ErrorCount     int                    `json:"error_count"`
+	Errors         []FieldValidationError `json:"errors"`
+}
@@ -0,0 +1,75 @@
+package apierror
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes, stable values clients can branch on
+const (
+	CodeInvalidRequest      = "invalid_request"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeNotFound            = "not_found"
+	CodeConflict            = "conflict"
+	CodeValidationFailed    = "validation_failed"
+	CodePreflightFailed     = "preflight_failed"
+	CodeQuotaExceeded       = "quota_exceeded"
+	CodeUpstreamError       = "upstream_error"       // Kafka Connect answered with an error
+	CodeUpstreamUnavailable = "upstream_unavailable" // Kafka Connect is unreachable, slow or rebalancing
+	CodeInternal            = "internal_error"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+	maxRequestID    = 128
+)
+
+// Error is the body of every error response, wrapped in Response
+type Error struct {
+	Code           string      `json:"code"`
+	Message        string      `json:"message"`
+	Details        interface{} `json:"details,omitempty"`
+	UpstreamStatus int         `json:"upstream_status,omitempty"` // status Kafka Connect answered with
+	RequestID      string      `json:"request_id,omitempty"`
+}
+
+type Response struct {
+	Error Error `json:"error"`
+}
+
+// Abort stops the request and answers with status and e, stamped with the request ID
+func Abort(c *gin.Context, status int, e Error) {
+	e.RequestID = RequestIDFrom(c)
+	c.AbortWithStatusJSON(status, Response{Error: e})
+}
+
+// RequestID keeps the caller's X-Request-ID or generates one, and echoes it
+// in the response so errors can be matched with the logs
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestID {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestIDFrom returns the ID RequestID assigned to the request, empty without the middleware
+func RequestIDFrom(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+type apiKeyAuthenticator struct {
+	keys map[string]string // key -> principal name
+}
+
+// NewAPIKeyAuthenticator accepts static keys from the X-API-Key header or an
+// "Authorization: ApiKey <key>" header. keys maps each key to its principal name.
+func NewAPIKeyAuthenticator(keys map[string]string) Authenticator {
+	return &apiKeyAuthenticator{keys: keys}
+}
+
+func (a *apiKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		if scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "ApiKey") {
+			key = strings.TrimSpace(value)
+		}
+	}
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	// Compare against every key so timing does not reveal which one matched
+	var subject string
+	for candidate, name := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			subject = name
+		}
+	}
+	if subject == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Principal{Subject: subject, Method: "api_key"}, nil
+}
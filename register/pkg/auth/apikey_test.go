@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	authenticator := NewAPIKeyAuthenticator(map[string]string{"ci-key": "ci"})
+
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{name: "X-API-Key", header: "X-API-Key", value: "ci-key", want: "ci"},
+		{name: "Authorization ApiKey", header: "Authorization", value: "ApiKey ci-key", want: "ci"},
+		{name: "unknown key", header: "X-API-Key", value: "other-key", wantErr: ErrInvalidCredentials},
+		{name: "key prefix", header: "X-API-Key", value: "ci-", wantErr: ErrInvalidCredentials},
+		{name: "malformed Authorization", header: "Authorization", value: "ApiKey", wantErr: ErrNoCredentials},
+		{name: "bearer token", header: "Authorization", value: "Bearer ci-key", wantErr: ErrNoCredentials},
+		{name: "no credentials", wantErr: ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/connectors", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			principal, err := authenticator.Authenticate(req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got principal %v, error %v, want %v", principal, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || principal.Subject != tt.want || principal.Method != "api_key" {
+				t.Fatalf("got principal %+v, error %v, want %s", principal, err, tt.want)
+			}
+		})
+	}
+}
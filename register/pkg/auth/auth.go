@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"register/pkg/apierror"
+	"register/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Key of the authenticated principal in the gin context
+const PrincipalKey = "principal"
+
+var (
+	// ErrNoCredentials means the authenticator found nothing it understands in the request
+	ErrNoCredentials      = errors.New("no credentials provided")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is the authenticated caller
+type Principal struct {
+	Subject string                 `json:"subject"`
+	Method  string                 `json:"method"` // api_key or jwt
+	Claims  map[string]interface{} `json:"claims,omitempty"`
+}
+
+func (p *Principal) String() string {
+	return p.Subject
+}
+
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type chain []Authenticator
+
+// Chain tries the authenticators in order, the first one that finds credentials decides
+func Chain(authenticators ...Authenticator) Authenticator {
+	return chain(authenticators)
+}
+
+func (c chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, a := range c {
+		principal, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// Middleware rejects unauthenticated requests and attaches the principal to the context
+func Middleware(authenticator Authenticator, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := authenticator.Authenticate(c.Request)
+		if err != nil {
+			log.Warn("Authentication failed",
+				logger.String("path", c.Request.URL.Path),
+				logger.String("client_ip", c.ClientIP()),
+				logger.Error(err),
+			)
+			c.Header("WWW-Authenticate", `Bearer realm="cdc-registration"`)
+			apierror.Abort(c, http.StatusUnauthorized, apierror.Error{Code: apierror.CodeUnauthorized, Message: err.Error()})
+			return
+		}
+
+		c.Set(PrincipalKey, principal)
+		c.Next()
+	}
+}
+
+// PrincipalFrom returns the authenticated principal or nil
+func PrincipalFrom(c *gin.Context) *Principal {
+	value, ok := c.Get(PrincipalKey)
+	if !ok {
+		return nil
+	}
+	principal, _ := value.(*Principal)
+	return principal
+}
+
+// SubjectFrom returns the authenticated subject, "anonymous" without authentication
+func SubjectFrom(c *gin.Context) string {
+	if principal := PrincipalFrom(c); principal != nil {
+		return principal.Subject
+	}
+	return "anonymous"
+}
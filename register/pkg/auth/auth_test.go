@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"register/pkg/apierror"
+	"register/pkg/logger"
+	"register/pkg/redact"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.NewZapLogger(redact.NewPolicy())
+	r := gin.New()
+	r.Use(apierror.RequestID(), Middleware(NewAPIKeyAuthenticator(map[string]string{"ci-key": "ci"}), log))
+	r.GET("/api/connectors", func(c *gin.Context) {
+		c.String(http.StatusOK, SubjectFrom(c))
+	})
+
+	tests := []struct {
+		name    string
+		apiKey  string
+		status  int
+		message string
+	}{
+		{name: "authenticated", apiKey: "ci-key", status: http.StatusOK},
+		{name: "no credentials", status: http.StatusUnauthorized, message: ErrNoCredentials.Error()},
+		{name: "unknown key", apiKey: "other-key", status: http.StatusUnauthorized, message: ErrInvalidCredentials.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/connectors", nil)
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status == http.StatusOK {
+				if w.Body.String() != "ci" {
+					t.Fatalf("got subject %q, want ci", w.Body.String())
+				}
+				return
+			}
+
+			if w.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("missing WWW-Authenticate header")
+			}
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode %q: %v", w.Body.String(), err)
+			}
+			if body.Error.Code != apierror.CodeUnauthorized || body.Error.Message != tt.message || body.Error.RequestID == "" {
+				t.Fatalf("got error %+v, want %s with %q and a request ID", body.Error, apierror.CodeUnauthorized, tt.message)
+			}
+		})
+	}
+}
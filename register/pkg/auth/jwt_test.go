@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "cdc-registration"
+	testKid      = "key-1"
+)
+
+// newTestJWKS writes a JWKS file with the public half of a new RSA key
+func newTestJWKS(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": testKid,
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	data, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return key, file
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub": "ci",
+		"iss": testIssuer,
+		"aud": testAudience,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, claims jwt.MapClaims, key interface{}) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	key, jwksFile := newTestJWKS(t)
+	authenticator, err := NewJWTAuthenticator(jwksFile, testIssuer, testAudience)
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+
+	with := func(change func(jwt.MapClaims)) jwt.MapClaims {
+		claims := validClaims()
+		change(claims)
+		return claims
+	}
+	hour := time.Hour
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "valid", token: sign(t, jwt.SigningMethodRS256, testKid, validClaims(), key)},
+		{name: "expired", token: sign(t, jwt.SigningMethodRS256, testKid, with(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-hour).Unix() }), key), wantErr: ErrInvalidCredentials},
+		{name: "not yet valid", token: sign(t, jwt.SigningMethodRS256, testKid, with(func(c jwt.MapClaims) { c["nbf"] = time.Now().Add(hour).Unix() }), key), wantErr: ErrInvalidCredentials},
+		{name: "without expiry", token: sign(t, jwt.SigningMethodRS256, testKid, with(func(c jwt.MapClaims) { delete(c, "exp") }), key), wantErr: ErrInvalidCredentials},
+		{name: "alg none", token: sign(t, jwt.SigningMethodNone, testKid, validClaims(), jwt.UnsafeAllowNoneSignatureType), wantErr: ErrInvalidCredentials},
+		{name: "HS256 keyed with the RSA public key", token: sign(t, jwt.SigningMethodHS256, testKid, validClaims(), key.PublicKey.N.Bytes()), wantErr: ErrInvalidCredentials},
+		{name: "unknown kid", token: sign(t, jwt.SigningMethodRS256, "key-2", validClaims(), key), wantErr: ErrInvalidCredentials},
+		{name: "issuer mismatch", token: sign(t, jwt.SigningMethodRS256, testKid, with(func(c jwt.MapClaims) { c["iss"] = "https://other.example.com" }), key), wantErr: ErrInvalidCredentials},
+		{name: "audience mismatch", token: sign(t, jwt.SigningMethodRS256, testKid, with(func(c jwt.MapClaims) { c["aud"] = "other-service" }), key), wantErr: ErrInvalidCredentials},
+		{name: "missing sub", token: sign(t, jwt.SigningMethodRS256, testKid, with(func(c jwt.MapClaims) { delete(c, "sub") }), key), wantErr: ErrInvalidCredentials},
+		{name: "malformed", token: "not.a.token", wantErr: ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/connectors", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			principal, err := authenticator.Authenticate(req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got principal %v, error %v, want %v", principal, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || principal.Subject != "ci" || principal.Method != "jwt" {
+				t.Fatalf("got principal %+v, error %v, want ci authenticated by jwt", principal, err)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticatorIgnoresOtherSchemes(t *testing.T) {
+	_, jwksFile := newTestJWKS(t)
+	authenticator, err := NewJWTAuthenticator(jwksFile, "", "")
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/connectors", nil)
+	req.Header.Set("Authorization", "ApiKey secret")
+	if _, err := authenticator.Authenticate(req); !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("got %v, want ErrNoCredentials", err)
+	}
+}
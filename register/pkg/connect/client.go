@@ -0,0 +1,280 @@
+package connect
+
+import (
+	"context"
+	"net/url"
+	"register/pkg/http"
+	"strconv"
+	"strings"
+)
+
+// Client is a typed client for the Kafka Connect REST API. Error responses
+// are returned as *Error, options bound the single call.
+type Client interface {
+	ServerInfo(ctx context.Context, opts ...http.Option) (*ServerInfo, error)
+
+	ConnectorNames(ctx context.Context, opts ...http.Option) ([]string, error)
+	ExpandedConnectors(ctx context.Context, opts ...http.Option) (map[string]ExpandedConnector, error)
+	CreateConnector(ctx context.Context, req CreateConnectorRequest, opts ...http.Option) (*ConnectorInfo, error)
+	Connector(ctx context.Context, name string, opts ...http.Option) (*ConnectorInfo, error)
+	ConnectorConfig(ctx context.Context, name string, opts ...http.Option) (map[string]string, error)
+	PutConnectorConfig(ctx context.Context, name string, config map[string]string, opts ...http.Option) (*ConnectorInfo, error)
+	ConnectorStatus(ctx context.Context, name string, opts ...http.Option) (*ConnectorStatus, error)
+	RestartConnector(ctx context.Context, name string, restart RestartOptions, opts ...http.Option) error
+	PauseConnector(ctx context.Context, name string, opts ...http.Option) error
+	ResumeConnector(ctx context.Context, name string, opts ...http.Option) error
+	StopConnector(ctx context.Context, name string, opts ...http.Option) error
+	DeleteConnector(ctx context.Context, name string, opts ...http.Option) error
+
+	Tasks(ctx context.Context, name string, opts ...http.Option) ([]TaskInfo, error)
+	TaskStatus(ctx context.Context, name string, taskID int, opts ...http.Option) (*TaskState, error)
+	RestartTask(ctx context.Context, name string, taskID int, opts ...http.Option) error
+
+	Topics(ctx context.Context, name string, opts ...http.Option) ([]string, error)
+	ResetTopics(ctx context.Context, name string, opts ...http.Option) error
+
+	Offsets(ctx context.Context, name string, opts ...http.Option) (*ConnectorOffsets, error)
+	AlterOffsets(ctx context.Context, name string, offsets ConnectorOffsets, opts ...http.Option) (*Message, error)
+	ResetOffsets(ctx context.Context, name string, opts ...http.Option) error
+
+	Plugins(ctx context.Context, opts ...http.Option) ([]PluginInfo, error)
+	PluginConfig(ctx context.Context, class string, opts ...http.Option) ([]ConfigKeyInfo, error)
+	ValidateConfig(ctx context.Context, class string, config map[string]string, opts ...http.Option) (*ConfigInfos, error)
+
+	Loggers(ctx context.Context, opts ...http.Option) (map[string]LoggerLevel, error)
+	Logger(ctx context.Context, logger string, opts ...http.Option) (*LoggerLevel, error)
+	SetLogLevel(ctx context.Context, logger, level string, opts ...http.Option) ([]string, error)
+}
+
+type client struct {
+	baseURL string
+	http    http.HTTPClient
+}
+
+func NewClient(baseURL string, c http.HTTPClient) Client {
+	return &client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    c,
+	}
+}
+
+// url joins the escaped path segments to the base URL
+func (c *client) url(segments ...string) string {
+	var b strings.Builder
+	b.WriteString(c.baseURL)
+	for _, segment := range segments {
+		b.WriteByte('/')
+		b.WriteString(url.PathEscape(segment))
+	}
+	return b.String()
+}
+
+func (c *client) get(ctx context.Context, endpoint string, result interface{}, opts []http.Option) error {
+	return wrap(c.http.Get(ctx, endpoint, result, opts...))
+}
+
+func (c *client) post(ctx context.Context, endpoint string, body, result interface{}, opts []http.Option) error {
+	return wrap(c.http.Post(ctx, endpoint, body, result, opts...))
+}
+
+func (c *client) put(ctx context.Context, endpoint string, body, result interface{}, opts []http.Option) error {
+	return wrap(c.http.Put(ctx, endpoint, body, result, opts...))
+}
+
+func (c *client) ServerInfo(ctx context.Context, opts ...http.Option) (*ServerInfo, error) {
+	var info ServerInfo
+	if err := c.get(ctx, c.baseURL+"/", &info, opts); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *client) ConnectorNames(ctx context.Context, opts ...http.Option) ([]string, error) {
+	var names []string
+	if err := c.get(ctx, c.url("connectors"), &names, opts); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (c *client) ExpandedConnectors(ctx context.Context, opts ...http.Option) (map[string]ExpandedConnector, error) {
+	connectors := make(map[string]ExpandedConnector)
+	if err := c.get(ctx, c.url("connectors")+"?expand=info&expand=status", &connectors, opts); err != nil {
+		return nil, err
+	}
+	return connectors, nil
+}
+
+func (c *client) CreateConnector(ctx context.Context, req CreateConnectorRequest, opts ...http.Option) (*ConnectorInfo, error) {
+	var info ConnectorInfo
+	if err := c.post(ctx, c.url("connectors"), req, &info, opts); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *client) Connector(ctx context.Context, name string, opts ...http.Option) (*ConnectorInfo, error) {
+	var info ConnectorInfo
+	if err := c.get(ctx, c.url("connectors", name), &info, opts); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *client) ConnectorConfig(ctx context.Context, name string, opts ...http.Option) (map[string]string, error) {
+	config := make(map[string]string)
+	if err := c.get(ctx, c.url("connectors", name, "config"), &config, opts); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// PutConnectorConfig creates the connector or replaces its whole config
+func (c *client) PutConnectorConfig(ctx context.Context, name string, config map[string]string, opts ...http.Option) (*ConnectorInfo, error) {
+	var info ConnectorInfo
+	if err := c.put(ctx, c.url("connectors", name, "config"), config, &info, opts); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *client) ConnectorStatus(ctx context.Context, name string, opts ...http.Option) (*ConnectorStatus, error) {
+	var status ConnectorStatus
+	if err := c.get(ctx, c.url("connectors", name, "status"), &status, opts); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *client) RestartConnector(ctx context.Context, name string, restart RestartOptions, opts ...http.Option) error {
+	query := url.Values{}
+	query.Set("includeTasks", strconv.FormatBool(restart.IncludeTasks))
+	query.Set("onlyFailed", strconv.FormatBool(restart.OnlyFailed))
+	return c.post(ctx, c.url("connectors", name, "restart")+"?"+query.Encode(), nil, nil, opts)
+}
+
+func (c *client) PauseConnector(ctx context.Context, name string, opts ...http.Option) error {
+	return c.put(ctx, c.url("connectors", name, "pause"), nil, nil, opts)
+}
+
+func (c *client) ResumeConnector(ctx context.Context, name string, opts ...http.Option) error {
+	return c.put(ctx, c.url("connectors", name, "resume"), nil, nil, opts)
+}
+
+// StopConnector shuts the connector and its tasks down but keeps its config,
+// offsets can only be altered or reset while it is stopped
+func (c *client) StopConnector(ctx context.Context, name string, opts ...http.Option) error {
+	return c.put(ctx, c.url("connectors", name, "stop"), nil, nil, opts)
+}
+
+// DeleteConnector succeeds for a connector that does not exist
+func (c *client) DeleteConnector(ctx context.Context, name string, opts ...http.Option) error {
+	return wrap(c.http.Delete(ctx, c.url("connectors", name), opts...))
+}
+
+func (c *client) Tasks(ctx context.Context, name string, opts ...http.Option) ([]TaskInfo, error) {
+	var tasks []TaskInfo
+	if err := c.get(ctx, c.url("connectors", name, "tasks"), &tasks, opts); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (c *client) TaskStatus(ctx context.Context, name string, taskID int, opts ...http.Option) (*TaskState, error) {
+	var state TaskState
+	if err := c.get(ctx, c.url("connectors", name, "tasks", strconv.Itoa(taskID), "status"), &state, opts); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (c *client) RestartTask(ctx context.Context, name string, taskID int, opts ...http.Option) error {
+	return c.post(ctx, c.url("connectors", name, "tasks", strconv.Itoa(taskID), "restart"), nil, nil, opts)
+}
+
+func (c *client) Topics(ctx context.Context, name string, opts ...http.Option) ([]string, error) {
+	var topics map[string]ConnectorTopics
+	if err := c.get(ctx, c.url("connectors", name, "topics"), &topics, opts); err != nil {
+		return nil, err
+	}
+	return topics[name].Topics, nil
+}
+
+func (c *client) ResetTopics(ctx context.Context, name string, opts ...http.Option) error {
+	return c.put(ctx, c.url("connectors", name, "topics", "reset"), nil, nil, opts)
+}
+
+func (c *client) Offsets(ctx context.Context, name string, opts ...http.Option) (*ConnectorOffsets, error) {
+	var offsets ConnectorOffsets
+	if err := c.get(ctx, c.url("connectors", name, "offsets"), &offsets, opts); err != nil {
+		return nil, err
+	}
+	return &offsets, nil
+}
+
+// AlterOffsets requires the connector to be stopped
+func (c *client) AlterOffsets(ctx context.Context, name string, offsets ConnectorOffsets, opts ...http.Option) (*Message, error) {
+	var message Message
+	if err := wrap(c.http.Patch(ctx, c.url("connectors", name, "offsets"), offsets, &message, opts...)); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// ResetOffsets requires the connector to be stopped
+func (c *client) ResetOffsets(ctx context.Context, name string, opts ...http.Option) error {
+	return wrap(c.http.Delete(ctx, c.url("connectors", name, "offsets"), opts...))
+}
+
+func (c *client) Plugins(ctx context.Context, opts ...http.Option) ([]PluginInfo, error) {
+	var plugins []PluginInfo
+	if err := c.get(ctx, c.url("connector-plugins"), &plugins, opts); err != nil {
+		return nil, err
+	}
+	return plugins, nil
+}
+
+func (c *client) PluginConfig(ctx context.Context, class string, opts ...http.Option) ([]ConfigKeyInfo, error) {
+	var keys []ConfigKeyInfo
+	if err := c.get(ctx, c.url("connector-plugins", class, "config"), &keys, opts); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ValidateConfig validates config against the plugin class. Invalid configs are
+// not an error, they are reported in ConfigInfos.ErrorCount.
+func (c *client) ValidateConfig(ctx context.Context, class string, config map[string]string, opts ...http.Option) (*ConfigInfos, error) {
+	var infos ConfigInfos
+	if err := c.put(ctx, c.url("connector-plugins", class, "config", "validate"), config, &infos, opts); err != nil {
+		return nil, err
+	}
+	return &infos, nil
+}
+
+func (c *client) Loggers(ctx context.Context, opts ...http.Option) (map[string]LoggerLevel, error) {
+	loggers := make(map[string]LoggerLevel)
+	if err := c.get(ctx, c.url("admin", "loggers"), &loggers, opts); err != nil {
+		return nil, err
+	}
+	return loggers, nil
+}
+
+func (c *client) Logger(ctx context.Context, logger string, opts ...http.Option) (*LoggerLevel, error) {
+	var level LoggerLevel
+	if err := c.get(ctx, c.url("admin", "loggers", logger), &level, opts); err != nil {
+		return nil, err
+	}
+	return &level, nil
+}
+
+// SetLogLevel sets the level of logger and its children on this worker and
+// returns the loggers that changed
+func (c *client) SetLogLevel(ctx context.Context, logger, level string, opts ...http.Option) ([]string, error) {
+	var modified []string
+	body := map[string]string{"level": level}
+	if err := c.put(ctx, c.url("admin", "loggers", logger), body, &modified, opts); err != nil {
+		return nil, err
+	}
+	return modified, nil
+}
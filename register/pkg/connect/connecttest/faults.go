@@ -0,0 +1,100 @@
+package connecttest
+
+import (
+	"net/http"
+	"time"
+)
+
+// RebalanceMessage is the message Kafka Connect answers 409 with while its
+// workers rebalance
+const RebalanceMessage = "Cannot complete request momentarily due to stale configuration (typically caused by a concurrent config change)"
+
+// Fault makes matching requests fail before they reach the fake
+type Fault struct {
+	Method  string // empty matches every method
+	Path    string // exact request path, e.g. /connectors/orders/status, empty matches every path
+	Status  int
+	Message string
+	Times   int // requests to fail, 0 fails every matching request until ClearFaults
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+	return (f.Method == "" || f.Method == r.Method) && (f.Path == "" || f.Path == r.URL.Path)
+}
+
+// InjectFault makes requests matching fault fail with its status and message.
+// Faults are checked in the order they were injected.
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// Rebalance answers the next times requests with 409, as Kafka Connect does
+// while its workers rebalance
+func (s *Server) Rebalance(times int) {
+	s.InjectFault(Fault{Status: http.StatusConflict, Message: RebalanceMessage, Times: times})
+}
+
+// ClearFaults removes all injected faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// SetLatency delays every response by latency
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = latency
+}
+
+// Calls returns how many requests for method and path the server received,
+// failed ones included
+func (s *Server) Calls(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method+" "+path]
+}
+
+// intercept counts requests and applies latency and faults before next
+func (s *Server) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.calls[r.Method+" "+r.URL.Path]++
+		latency := s.latency
+		fault := s.fault(r)
+		s.mu.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(latency):
+			}
+		}
+		if fault != nil {
+			writeError(w, fault.Status, fault.Message)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// fault returns the first fault matching r and uses it up, s.mu must be held
+func (s *Server) fault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if !f.matches(r) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
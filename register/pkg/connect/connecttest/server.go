@@ -0,0 +1,625 @@
+// Package connecttest provides an in-memory Kafka Connect REST server for tests
+package connecttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"register/pkg/connect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPlugins are offered by every Server, the Debezium connectors the service registers
+var DefaultPlugins = []connect.PluginInfo{
+	{Class: "io.debezium.connector.mongodb.MongoDbConnector", Type: "source", Version: "2.7.0.Final"},
+	{Class: "io.debezium.connector.mysql.MySqlConnector", Type: "source", Version: "2.7.0.Final"},
+	{Class: "io.debezium.connector.oracle.OracleConnector", Type: "source", Version: "2.7.0.Final"},
+	{Class: "io.debezium.connector.postgresql.PostgreSqlConnector", Type: "source", Version: "2.7.0.Final"},
+	{Class: "io.debezium.connector.sqlserver.SqlServerConnector", Type: "source", Version: "2.7.0.Final"},
+}
+
+const workerID = "connect-0:8083"
+
+type connector struct {
+	config  map[string]string
+	state   connect.ConnectorState
+	tasks   []connect.TaskState
+	topics  []string
+	offsets []connect.Offset
+}
+
+// Server is an in-memory Kafka Connect. Connectors start RUNNING with
+// tasks.max RUNNING tasks, tests move them to other states with SetConnectorState
+// and SetTaskState, and make requests slow or fail with SetLatency, InjectFault
+// and Rebalance.
+type Server struct {
+	*httptest.Server
+
+	mu               sync.Mutex
+	connectors       map[string]*connector
+	plugins          []connect.PluginInfo
+	loggers          map[string]connect.LoggerLevel
+	validationErrors map[string][]string
+	taskState        connect.TaskState // state new tasks start in
+	latency          time.Duration
+	faults           []*Fault
+	calls            map[string]int
+}
+
+// NewServer starts a Server, callers must Close it
+func NewServer() *Server {
+	s := &Server{
+		connectors:       make(map[string]*connector),
+		plugins:          DefaultPlugins,
+		loggers:          map[string]connect.LoggerLevel{"root": {Level: "INFO"}},
+		validationErrors: make(map[string][]string),
+		taskState:        connect.TaskState{State: connect.StateRunning, WorkerID: workerID},
+		calls:            make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.serverInfo)
+	mux.HandleFunc("GET /connectors", s.listConnectors)
+	mux.HandleFunc("POST /connectors", s.createConnector)
+	mux.HandleFunc("GET /connectors/{name}", s.getConnector)
+	mux.HandleFunc("DELETE /connectors/{name}", s.deleteConnector)
+	mux.HandleFunc("GET /connectors/{name}/config", s.getConfig)
+	mux.HandleFunc("PUT /connectors/{name}/config", s.putConfig)
+	mux.HandleFunc("GET /connectors/{name}/status", s.getStatus)
+	mux.HandleFunc("POST /connectors/{name}/restart", s.restartConnector)
+	mux.HandleFunc("PUT /connectors/{name}/pause", s.setState(connect.StatePaused))
+	mux.HandleFunc("PUT /connectors/{name}/resume", s.setState(connect.StateRunning))
+	mux.HandleFunc("PUT /connectors/{name}/stop", s.setState(connect.StateStopped))
+	mux.HandleFunc("GET /connectors/{name}/tasks", s.getTasks)
+	mux.HandleFunc("GET /connectors/{name}/tasks/{id}/status", s.getTaskStatus)
+	mux.HandleFunc("POST /connectors/{name}/tasks/{id}/restart", s.restartTask)
+	mux.HandleFunc("GET /connectors/{name}/topics", s.getTopics)
+	mux.HandleFunc("PUT /connectors/{name}/topics/reset", s.resetTopics)
+	mux.HandleFunc("GET /connectors/{name}/offsets", s.getOffsets)
+	mux.HandleFunc("PATCH /connectors/{name}/offsets", s.alterOffsets)
+	mux.HandleFunc("DELETE /connectors/{name}/offsets", s.resetOffsets)
+	mux.HandleFunc("GET /connector-plugins", s.listPlugins)
+	mux.HandleFunc("GET /connector-plugins/{class}/config", s.getPluginConfig)
+	mux.HandleFunc("PUT /connector-plugins/{class}/config/validate", s.validateConfig)
+	mux.HandleFunc("GET /admin/loggers", s.listLoggers)
+	mux.HandleFunc("GET /admin/loggers/{logger}", s.getLogger)
+	mux.HandleFunc("PUT /admin/loggers/{logger}", s.setLogLevel)
+
+	s.Server = httptest.NewServer(s.intercept(mux))
+	return s
+}
+
+// SetConnectorState sets the state and trace of a connector, it returns false
+// for an unknown connector
+func (s *Server) SetConnectorState(name, state, trace string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.connectors[name]
+	if !ok {
+		return false
+	}
+	c.state.State = state
+	c.state.Trace = trace
+	return true
+}
+
+// SetTaskState sets the state and trace of a task, it returns false for an
+// unknown connector or task
+func (s *Server) SetTaskState(name string, taskID int, state, trace string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.connectors[name]
+	if !ok || taskID < 0 || taskID >= len(c.tasks) {
+		return false
+	}
+	c.tasks[taskID].State = state
+	c.tasks[taskID].Trace = trace
+	return true
+}
+
+// SetInitialTaskState makes the tasks of connectors created from now on start
+// in state with trace, e.g. UNASSIGNED to keep a registration pending or FAILED
+// to simulate a connector that cannot reach its database
+func (s *Server) SetInitialTaskState(state, trace string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taskState = connect.TaskState{State: state, WorkerID: workerID, Trace: trace}
+}
+
+// SetValidationErrors makes config validation report errs for key on every plugin
+func (s *Server) SetValidationErrors(key string, errs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.validationErrors[key] = errs
+}
+
+// ConnectorConfig returns a copy of the config of a connector, nil for an unknown connector
+func (s *Server) ConnectorConfig(name string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.connectors[name]
+	if !ok {
+		return nil
+	}
+	config := make(map[string]string, len(c.config))
+	for k, v := range c.config {
+		config[k] = v
+	}
+	return config
+}
+
+// ConnectorNames returns the names of all connectors, sorted
+func (s *Server) ConnectorNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.names()
+}
+
+func (s *Server) names() []string {
+	names := make([]string, 0, len(s.connectors))
+	for name := range s.connectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup returns the connector named in the request path, it answers 404 and
+// returns nil if there is none. s.mu must be held.
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) *connector {
+	name := r.PathValue("name")
+	c, ok := s.connectors[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Connector %s not found", name))
+		return nil
+	}
+	return c
+}
+
+func (s *Server) serverInfo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, connect.ServerInfo{Version: "3.8.0", Commit: "fake", KafkaClusterID: "connecttest"})
+}
+
+func (s *Server) listConnectors(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expand := r.URL.Query()["expand"]
+	if len(expand) == 0 {
+		writeJSON(w, http.StatusOK, s.names())
+		return
+	}
+
+	expanded := make(map[string]connect.ExpandedConnector, len(s.connectors))
+	for name, c := range s.connectors {
+		var e connect.ExpandedConnector
+		for _, field := range expand {
+			switch field {
+			case "info":
+				e.Info = c.info(name)
+			case "status":
+				e.Status = c.status(name)
+			}
+		}
+		expanded[name] = e
+	}
+	writeJSON(w, http.StatusOK, expanded)
+}
+
+func (s *Server) createConnector(w http.ResponseWriter, r *http.Request) {
+	var req connect.CreateConnectorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Request body is not valid JSON: "+err.Error())
+		return
+	}
+	if req.Name == "" || req.Config == nil {
+		writeError(w, http.StatusBadRequest, "Connector name and config are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.connectors[req.Name]; ok {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Connector %s already exists", req.Name))
+		return
+	}
+	c := s.newConnector(req.Name, req.Config)
+	s.connectors[req.Name] = c
+	writeJSON(w, http.StatusCreated, c.info(req.Name))
+}
+
+func (s *Server) getConnector(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.lookup(w, r); c != nil {
+		writeJSON(w, http.StatusOK, c.info(r.PathValue("name")))
+	}
+}
+
+func (s *Server) deleteConnector(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.lookup(w, r); c != nil {
+		delete(s.connectors, r.PathValue("name"))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) getConfig(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.lookup(w, r); c != nil {
+		writeJSON(w, http.StatusOK, c.config)
+	}
+}
+
+// putConfig replaces the config of a connector or creates it
+func (s *Server) putConfig(w http.ResponseWriter, r *http.Request) {
+	var config map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		writeError(w, http.StatusBadRequest, "Request body is not valid JSON: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := r.PathValue("name")
+	c, ok := s.connectors[name]
+	if !ok {
+		c = s.newConnector(name, config)
+		s.connectors[name] = c
+		writeJSON(w, http.StatusCreated, c.info(name))
+		return
+	}
+	updated := s.newConnector(name, config)
+	updated.state.State = c.state.State
+	*c = *updated
+	writeJSON(w, http.StatusOK, c.info(name))
+}
+
+func (s *Server) getStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.lookup(w, r); c != nil {
+		writeJSON(w, http.StatusOK, c.status(r.PathValue("name")))
+	}
+}
+
+func (s *Server) restartConnector(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.lookup(w, r)
+	if c == nil {
+		return
+	}
+	query := r.URL.Query()
+	onlyFailed := query.Get("onlyFailed") == "true"
+	if !onlyFailed || c.state.State == connect.StateFailed {
+		c.state = connect.ConnectorState{State: connect.StateRunning, WorkerID: workerID}
+	}
+	if query.Get("includeTasks") == "true" {
+		for i := range c.tasks {
+			if !onlyFailed || c.tasks[i].State == connect.StateFailed {
+				c.tasks[i] = connect.TaskState{ID: i, State: connect.StateRunning, WorkerID: workerID}
+			}
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) setState(state string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		c := s.lookup(w, r)
+		if c == nil {
+			return
+		}
+		c.state.State = state
+		c.state.Trace = ""
+		for i := range c.tasks {
+			c.tasks[i].State = state
+			c.tasks[i].Trace = ""
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (s *Server) getTasks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.lookup(w, r)
+	if c == nil {
+		return
+	}
+	name := r.PathValue("name")
+	tasks := make([]connect.TaskInfo, len(c.tasks))
+	for i := range c.tasks {
+		tasks[i] = connect.TaskInfo{ID: connect.TaskID{Connector: name, Task: i}, Config: c.config}
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// task returns the task in the request path, it answers 404 and returns -1 if there is none
+func (s *Server) task(w http.ResponseWriter, r *http.Request, c *connector) int {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 0 || id >= len(c.tasks) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Task %s/%s not found", r.PathValue("name"), r.PathValue("id")))
+		return -1
+	}
+	return id
+}
+
+func (s *Server) getTaskStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.lookup(w, r)
+	if c == nil {
+		return
+	}
+	if id := s.task(w, r, c); id >= 0 {
+		writeJSON(w, http.StatusOK, c.tasks[id])
+	}
+}
+
+func (s *Server) restartTask(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.lookup(w, r)
+	if c == nil {
+		return
+	}
+	if id := s.task(w, r, c); id >= 0 {
+		c.tasks[id] = connect.TaskState{ID: id, State: connect.StateRunning, WorkerID: workerID}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) getTopics(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.lookup(w, r); c != nil {
+		writeJSON(w, http.StatusOK, map[string]connect.ConnectorTopics{r.PathValue("name"): {Topics: c.topics}})
+	}
+}
+
+func (s *Server) resetTopics(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.lookup(w, r); c != nil {
+		c.topics = []string{}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (s *Server) getOffsets(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.lookup(w, r); c != nil {
+		writeJSON(w, http.StatusOK, connect.ConnectorOffsets{Offsets: c.offsets})
+	}
+}
+
+func (s *Server) alterOffsets(w http.ResponseWriter, r *http.Request) {
+	var req connect.ConnectorOffsets
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Request body is not valid JSON: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.lookup(w, r)
+	if c == nil || !stopped(w, c) {
+		return
+	}
+	for _, offset := range req.Offsets {
+		c.offsets = setOffset(c.offsets, offset)
+	}
+	writeJSON(w, http.StatusOK, connect.Message{Message: "The offsets for this connector have been altered successfully"})
+}
+
+func (s *Server) resetOffsets(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.lookup(w, r)
+	if c == nil || !stopped(w, c) {
+		return
+	}
+	c.offsets = []connect.Offset{}
+	writeJSON(w, http.StatusOK, connect.Message{Message: "The offsets for this connector have been reset successfully"})
+}
+
+func (s *Server) listPlugins(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.plugins)
+}
+
+func (s *Server) getPluginConfig(w http.ResponseWriter, r *http.Request) {
+	if !s.hasPlugin(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, []connect.ConfigKeyInfo{
+		{Name: "connector.class", Type: "STRING", Required: true, Importance: "HIGH", Group: "Common"},
+		{Name: "tasks.max", Type: "INT", Importance: "HIGH", Group: "Common"},
+	})
+}
+
+// validateConfig reports the errors set with SetValidationErrors for the keys in the config
+func (s *Server) validateConfig(w http.ResponseWriter, r *http.Request) {
+	if !s.hasPlugin(w, r) {
+		return
+	}
+	var config map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		writeError(w, http.StatusBadRequest, "Request body is not valid JSON: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos := connect.ConfigInfos{Name: r.PathValue("class"), Groups: []string{"Common"}}
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := config[key]
+		errs := append([]string{}, s.validationErrors[key]...)
+		infos.ErrorCount += len(errs)
+		infos.Configs = append(infos.Configs, connect.ConfigInfo{
+			Value: connect.ConfigValueInfo{Name: key, Value: &value, RecommendedValues: []string{}, Errors: errs, Visible: true},
+		})
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+func (s *Server) hasPlugin(w http.ResponseWriter, r *http.Request) bool {
+	class := r.PathValue("class")
+	for _, plugin := range s.plugins {
+		if plugin.Class == class || strings.HasSuffix(plugin.Class, "."+class) {
+			return true
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("Failed to find any class that implements Connector and which name matches %s", class))
+	return false
+}
+
+func (s *Server) listLoggers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.loggers)
+}
+
+func (s *Server) getLogger(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := r.PathValue("logger")
+	level, ok := s.loggers[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Logger %s not found", name))
+		return
+	}
+	writeJSON(w, http.StatusOK, level)
+}
+
+func (s *Server) setLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Level string `json:"level"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Level == "" {
+		writeError(w, http.StatusBadRequest, "Desired 'level' parameter was not specified in request")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := r.PathValue("logger")
+	s.loggers[name] = connect.LoggerLevel{Level: strings.ToUpper(req.Level)}
+	writeJSON(w, http.StatusOK, []string{name})
+}
+
+// newConnector creates the state of a connector, s.mu must be held
+func (s *Server) newConnector(name string, config map[string]string) *connector {
+	config["name"] = name
+	tasksMax, err := strconv.Atoi(config["tasks.max"])
+	if err != nil || tasksMax < 1 {
+		tasksMax = 1
+	}
+
+	c := &connector{
+		config:  config,
+		state:   connect.ConnectorState{State: connect.StateRunning, WorkerID: workerID},
+		topics:  []string{},
+		offsets: []connect.Offset{},
+	}
+	for i := 0; i < tasksMax; i++ {
+		task := s.taskState
+		task.ID = i
+		c.tasks = append(c.tasks, task)
+	}
+	return c
+}
+
+func (c *connector) info(name string) *connect.ConnectorInfo {
+	info := &connect.ConnectorInfo{Name: name, Config: c.config, Tasks: []connect.TaskID{}, Type: "source"}
+	for i := range c.tasks {
+		info.Tasks = append(info.Tasks, connect.TaskID{Connector: name, Task: i})
+	}
+	return info
+}
+
+func (c *connector) status(name string) *connect.ConnectorStatus {
+	return &connect.ConnectorStatus{
+		Name:      name,
+		Connector: c.state,
+		Tasks:     append([]connect.TaskState{}, c.tasks...),
+		Type:      "source",
+	}
+}
+
+// stopped answers 400 and returns false unless the connector is stopped, as
+// Connect does for offset changes
+func stopped(w http.ResponseWriter, c *connector) bool {
+	if c.state.State != connect.StateStopped {
+		writeError(w, http.StatusBadRequest, "Connectors must be in the STOPPED state before their offsets can be modified")
+		return false
+	}
+	return true
+}
+
+// setOffset replaces the offset of the partition, a nil offset removes it
+func setOffset(offsets []connect.Offset, offset connect.Offset) []connect.Offset {
+	key, _ := json.Marshal(offset.Partition)
+	for i, existing := range offsets {
+		if existingKey, _ := json.Marshal(existing.Partition); string(existingKey) == string(key) {
+			if offset.Offset == nil {
+				return append(offsets[:i], offsets[i+1:]...)
+			}
+			offsets[i] = offset
+			return offsets
+		}
+	}
+	if offset.Offset == nil {
+		return offsets
+	}
+	return append(offsets, offset)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeError answers with the error body Kafka Connect uses
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"error_code": status, "message": message})
+}
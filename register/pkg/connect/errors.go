@@ -0,0 +1,83 @@
+package connect
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	registerhttp "register/pkg/http"
+	"strings"
+)
+
+// Error is an error response of the Connect REST API
+type Error struct {
+	StatusCode int    `json:"-"`
+	ErrorCode  int    `json:"error_code"`
+	Message    string `json:"message"`
+
+	err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("kafka connect error %d: %s", e.ErrorCode, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// IsNotFound reports whether Connect answered 404, e.g. for an unknown connector
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether Connect answered 409, either because the
+// connector exists or because the cluster is rebalancing
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsRebalancing reports whether Connect could not complete the request
+// because its workers are rebalancing or its config is stale. Retrying helps.
+func IsRebalancing(err error) bool {
+	var connectErr *Error
+	if !errors.As(err, &connectErr) || connectErr.StatusCode != http.StatusConflict {
+		return false
+	}
+	message := strings.ToLower(connectErr.Message)
+	return strings.Contains(message, "rebalanc") || strings.Contains(message, "stale")
+}
+
+// IsBadRequest reports whether Connect rejected the request or config
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest)
+}
+
+// IsRejected reports whether Connect answered with a client error, so the
+// request definitely had no effect. Server errors and timeouts leave that open.
+func IsRejected(err error) bool {
+	var connectErr *Error
+	return errors.As(err, &connectErr) && connectErr.StatusCode >= 400 && connectErr.StatusCode < 500
+}
+
+func hasStatus(err error, status int) bool {
+	var connectErr *Error
+	return errors.As(err, &connectErr) && connectErr.StatusCode == status
+}
+
+// wrap turns an HTTP error response into an Error, other errors are returned as is
+func wrap(err error) error {
+	var respErr *registerhttp.ResponseError
+	if !errors.As(err, &respErr) {
+		return err
+	}
+
+	connectErr := &Error{StatusCode: respErr.StatusCode, err: err}
+	if json.Unmarshal([]byte(respErr.Body), connectErr) != nil || connectErr.Message == "" {
+		connectErr.Message = respErr.Body
+	}
+	if connectErr.ErrorCode == 0 {
+		connectErr.ErrorCode = respErr.StatusCode
+	}
+	return connectErr
+}
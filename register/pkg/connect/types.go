@@ -0,0 +1,147 @@
+package connect
+
+// Connector and task states reported by the status endpoints
+const (
+	StateUnassigned = "UNASSIGNED"
+	StateRunning    = "RUNNING"
+	StatePaused     = "PAUSED"
+	StateStopped    = "STOPPED"
+	StateFailed     = "FAILED"
+	StateRestarting = "RESTARTING"
+)
+
+// ServerInfo is returned by GET /
+type ServerInfo struct {
+	Version        string `json:"version"`
+	Commit         string `json:"commit"`
+	KafkaClusterID string `json:"kafka_cluster_id"`
+}
+
+type TaskID struct {
+	Connector string `json:"connector"`
+	Task      int    `json:"task"`
+}
+
+// ConnectorInfo is returned by GET /connectors/{name} and the config endpoints
+type ConnectorInfo struct {
+	Name   string            `json:"name"`
+	Config map[string]string `json:"config"`
+	Tasks  []TaskID          `json:"tasks"`
+	Type   string            `json:"type,omitempty"`
+}
+
+type ConnectorState struct {
+	State    string `json:"state"`
+	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace,omitempty"`
+}
+
+type TaskState struct {
+	ID       int    `json:"id"`
+	State    string `json:"state"`
+	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace,omitempty"`
+}
+
+// ConnectorStatus is returned by GET /connectors/{name}/status
+type ConnectorStatus struct {
+	Name      string         `json:"name"`
+	Connector ConnectorState `json:"connector"`
+	Tasks     []TaskState    `json:"tasks"`
+	Type      string         `json:"type,omitempty"`
+}
+
+// ExpandedConnector is one entry of GET /connectors?expand=info&expand=status
+type ExpandedConnector struct {
+	Info   *ConnectorInfo   `json:"info,omitempty"`
+	Status *ConnectorStatus `json:"status,omitempty"`
+}
+
+// CreateConnectorRequest is the body of POST /connectors
+type CreateConnectorRequest struct {
+	Name   string            `json:"name"`
+	Config map[string]string `json:"config"`
+}
+
+// RestartOptions are the query parameters of POST /connectors/{name}/restart
+type RestartOptions struct {
+	IncludeTasks bool
+	OnlyFailed   bool
+}
+
+// TaskInfo is one entry of GET /connectors/{name}/tasks
+type TaskInfo struct {
+	ID     TaskID            `json:"id"`
+	Config map[string]string `json:"config"`
+}
+
+// ConnectorTopics is the value of GET /connectors/{name}/topics, keyed by connector name
+type ConnectorTopics struct {
+	Topics []string `json:"topics"`
+}
+
+// ConnectorOffsets is the body of GET and PATCH /connectors/{name}/offsets
+type ConnectorOffsets struct {
+	Offsets []Offset `json:"offsets"`
+}
+
+// Offset pairs a source partition with its offset, both connector specific.
+// A nil Offset in a PATCH resets the partition.
+type Offset struct {
+	Partition map[string]interface{} `json:"partition"`
+	Offset    map[string]interface{} `json:"offset"`
+}
+
+// Message is the body Connect answers offset changes with
+type Message struct {
+	Message string `json:"message"`
+}
+
+// PluginInfo is one entry of GET /connector-plugins
+type PluginInfo struct {
+	Class   string `json:"class"`
+	Type    string `json:"type"`
+	Version string `json:"version,omitempty"`
+}
+
+// ConfigKeyInfo describes one config key of a plugin
+type ConfigKeyInfo struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Required      bool     `json:"required"`
+	DefaultValue  *string  `json:"default_value"`
+	Importance    string   `json:"importance"`
+	Documentation string   `json:"documentation"`
+	Group         string   `json:"group"`
+	OrderInGroup  int      `json:"order_in_group"`
+	Width         string   `json:"width"`
+	DisplayName   string   `json:"display_name"`
+	Dependents    []string `json:"dependents"`
+}
+
+type ConfigValueInfo struct {
+	Name              string   `json:"name"`
+	Value             *string  `json:"value"`
+	RecommendedValues []string `json:"recommended_values"`
+	Errors            []string `json:"errors"`
+	Visible           bool     `json:"visible"`
+}
+
+type ConfigInfo struct {
+	Definition *ConfigKeyInfo  `json:"definition,omitempty"`
+	Value      ConfigValueInfo `json:"value"`
+}
+
+// ConfigInfos is returned by PUT /connector-plugins/{class}/config/validate
+type ConfigInfos struct {
+	Name       string       `json:"name"`
+	ErrorCount int          `json:"error_count"`
+	Groups     []string     `json:"groups"`
+	Configs    []ConfigInfo `json:"configs"`
+}
+
+// LoggerLevel is one entry of GET /admin/loggers
+type LoggerLevel struct {
+	Level        string `json:"level"`
+	LastModified *int64 `json:"last_modified,omitempty"`
+}
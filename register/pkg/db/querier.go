@@ -1,6 +1,8 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -20,10 +22,23 @@ func NewQueryBuilder(databaseURL string, logger logger.Logger) *QueryBuilder {
 	if err != nil {
 		logger.Fatal("Failed to connect to database", zap.Error(err))
 	}
+	if err := registerTracing(db); err != nil {
+		logger.Fatal("Failed to register database tracing", zap.Error(err))
+	}
+
+	return &QueryBuilder{db: db}
+}
 
+// NewQueryBuilderFromDB wraps an open connection, e.g. one backed by sqlmock in tests
+func NewQueryBuilderFromDB(db *gorm.DB) *QueryBuilder {
 	return &QueryBuilder{db: db}
 }
 
+// WithContext runs the following statements with ctx, for cancellation and tracing
+func (qb *QueryBuilder) WithContext(ctx context.Context) *QueryBuilder {
+	return &QueryBuilder{db: qb.db.WithContext(ctx)}
+}
+
 func (qb *QueryBuilder) AutoMigrate(dst ...interface{}) error {
 	return qb.db.AutoMigrate(dst...)
 }
@@ -52,6 +67,14 @@ func (qb *QueryBuilder) Model(value interface{}) *QueryBuilder {
 	return &QueryBuilder{db: qb.db.Model(value)}
 }
 
+func (qb *QueryBuilder) Select(query interface{}, args ...interface{}) *QueryBuilder {
+	return &QueryBuilder{db: qb.db.Select(query, args...)}
+}
+
+func (qb *QueryBuilder) Omit(columns ...string) *QueryBuilder {
+	return &QueryBuilder{db: qb.db.Omit(columns...)}
+}
+
 func (qb *QueryBuilder) Update(column string, value interface{}) *QueryBuilder {
 	return &QueryBuilder{db: qb.db.Update(column, value)}
 }
@@ -67,3 +90,7 @@ func (qb *QueryBuilder) Error() error {
 func (qb *QueryBuilder) RowsAffected() int64 {
 	return qb.db.RowsAffected
 }
+
+func IsNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound)
+}
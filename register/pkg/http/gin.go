@@ -3,10 +3,20 @@ package http
 import (
 	"go.uber.org/zap"
 	"net/http"
+	"register/pkg/apierror"
+	"register/pkg/auth"
 	"register/pkg/logger"
+	"register/pkg/metrics"
+	"register/pkg/tracing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func NewGinServer(logger logger.Logger) *gin.Engine {
@@ -14,16 +24,29 @@ func NewGinServer(logger logger.Logger) *gin.Engine {
 
 	r := gin.New()
 
-	// Middleware
-	r.Use(gin.Recovery())
+	// Middleware. Recovery runs innermost so the tracer and logger record a
+	// panicking request as a 500.
+	r.Use(apierror.RequestID())
+	r.Use(GinTracer())
 	r.Use(GinLogger(logger))
+	r.Use(gin.CustomRecovery(recovered))
+
+	r.NoRoute(func(c *gin.Context) {
+		apierror.Abort(c, http.StatusNotFound, apierror.Error{Code: apierror.CodeNotFound, Message: "no route for " + c.Request.Method + " " + c.Request.URL.Path})
+	})
 
 	// Health check
 	r.GET("/health", healthCheck)
+	r.GET("/metrics", metrics.Handler())
 
 	return r
 }
 
+// recovered answers a panicking request with the error envelope, gin has logged the panic
+func recovered(c *gin.Context, err any) {
+	apierror.Abort(c, http.StatusInternalServerError, apierror.Error{Code: apierror.CodeInternal, Message: "internal server error"})
+}
+
 func GinLogger(logger logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -31,6 +54,7 @@ func GinLogger(logger logger.Logger) gin.HandlerFunc {
 		c.Next()
 
 		duration := time.Since(start)
+		metrics.ObserveRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), duration)
 
 		logger.Info("HTTP Request",
 			zap.String("method", c.Request.Method),
@@ -38,10 +62,49 @@ func GinLogger(logger logger.Logger) gin.HandlerFunc {
 			zap.Int("status", c.Writer.Status()),
 			zap.String("duration", duration.String()),
 			zap.String("client_ip", c.ClientIP()),
+			zap.String("principal", auth.SubjectFrom(c)),
+			zap.String("trace_id", tracing.TraceID(c.Request.Context())),
+			zap.String("request_id", apierror.RequestIDFrom(c)),
 		)
 	}
 }
 
+// GinTracer starts a server span per request, continuing the caller's trace
+// when it sent a traceparent header. Handlers get the span through
+// c.Request.Context().
+func GinTracer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := tracing.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if principal := auth.SubjectFrom(c); principal != "" {
+			span.SetAttributes(attribute.String("enduser.id", principal))
+		}
+	}
+}
+
 func healthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "healthy",
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"register/pkg/apierror"
+	"register/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observedLogger records entries for assertions
+type observedLogger struct {
+	*zap.Logger
+}
+
+func (l observedLogger) Info(msg string, fields ...logger.Field)  { l.Logger.Info(msg, fields...) }
+func (l observedLogger) Error(msg string, fields ...logger.Field) { l.Logger.Error(msg, fields...) }
+func (l observedLogger) Warn(msg string, fields ...logger.Field)  { l.Logger.Warn(msg, fields...) }
+func (l observedLogger) Debug(msg string, fields ...logger.Field) { l.Logger.Debug(msg, fields...) }
+func (l observedLogger) Fatal(msg string, fields ...logger.Field) { l.Logger.Fatal(msg, fields...) }
+
+func TestPanicsAreTracedAndLoggedAsServerErrors(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// Keep the panic stack out of the test output
+	errorWriter := gin.DefaultErrorWriter
+	gin.DefaultErrorWriter = httptest.NewRecorder()
+	t.Cleanup(func() { gin.DefaultErrorWriter = errorWriter })
+
+	core, logs := observer.New(zap.InfoLevel)
+	r := NewGinServer(observedLogger{zap.New(core)})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status: got %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	var body apierror.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error.Code != apierror.CodeInternal {
+		t.Fatalf("body: got %s, want the internal error envelope", w.Body.String())
+	}
+
+	entries := logs.FilterMessage("HTTP Request").All()
+	if len(entries) != 1 || entries[0].ContextMap()["status"] != int64(http.StatusInternalServerError) {
+		t.Fatalf("log: got %+v, want one request logged with status 500", entries)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Fatalf("trace: got %d spans, want one ended with an error status", len(spans))
+	}
+}
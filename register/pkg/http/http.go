@@ -1,19 +1,52 @@
 package http
 
 import (
+	"context"
 	"fmt"
 	"go.uber.org/zap"
 	"register/pkg/logger"
+	"register/pkg/metrics"
+	"register/pkg/tracing"
+	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type HTTPClient interface {
-	Get(url string, result interface{}) error
-	Post(url string, body interface{}, result interface{}) error
-	Put(url string, body interface{}, result interface{}) error
-	Delete(url string) error
+	Get(ctx context.Context, url string, result interface{}, opts ...Option) error
+	Post(ctx context.Context, url string, body interface{}, result interface{}, opts ...Option) error
+	Put(ctx context.Context, url string, body interface{}, result interface{}, opts ...Option) error
+	Patch(ctx context.Context, url string, body interface{}, result interface{}, opts ...Option) error
+	Delete(ctx context.Context, url string, opts ...Option) error
+}
+
+// ResponseError is returned for responses with a 4xx or 5xx status
+type ResponseError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("HTTP error: %d - %s", e.StatusCode, e.Body)
+}
+
+// TransportError is returned when a request got no response at all, e.g.
+// because the connection was refused or the attempt timed out
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
 }
 
 type RestyClient struct {
@@ -21,11 +54,10 @@ type RestyClient struct {
 	logger logger.Logger
 }
 
+// NewRestyClient returns a client applying DefaultPolicy to calls without options.
+// Retries are done by the client itself, so every call can have its own policy.
 func NewRestyClient(logger logger.Logger) HTTPClient {
 	client := resty.New()
-	client.SetTimeout(30 * time.Second)
-	client.SetRetryCount(3)
-	client.SetRetryWaitTime(5 * time.Second)
 
 	// Add logging middleware
 	client.OnBeforeRequest(func(c *resty.Client, req *resty.Request) error {
@@ -42,74 +74,141 @@ func NewRestyClient(logger logger.Logger) HTTPClient {
 			zap.String("url", resp.Request.URL),
 			zap.Int("status", resp.StatusCode()),
 		)
+		metrics.ObserveConnectCall(resp.Request.Method, resp.Request.URL, resp.StatusCode(), resp.Time())
 		return nil
 	})
 
+	client.OnError(func(req *resty.Request, err error) {
+		if respErr, ok := err.(*resty.ResponseError); ok && respErr.Response.RawResponse != nil {
+			return // already observed with its status code
+		}
+		metrics.ObserveConnectError(req.Method, req.URL)
+	})
+
 	return &RestyClient{
 		client: client,
 		logger: logger,
 	}
 }
 
-func (r *RestyClient) Get(url string, result interface{}) error {
-	resp, err := r.client.R().
-		SetResult(result).
-		Get(url)
-
-	if err != nil {
-		return err
-	}
+func (r *RestyClient) Get(ctx context.Context, url string, result interface{}, opts ...Option) error {
+	_, err := r.do(ctx, resty.MethodGet, url, nil, result, opts)
+	return err
+}
 
-	if resp.IsError() {
-		return fmt.Errorf("HTTP error: %d - %s", resp.StatusCode(), resp.String())
-	}
+func (r *RestyClient) Post(ctx context.Context, url string, body interface{}, result interface{}, opts ...Option) error {
+	_, err := r.do(ctx, resty.MethodPost, url, body, result, opts)
+	return err
+}
 
-	return nil
+func (r *RestyClient) Put(ctx context.Context, url string, body interface{}, result interface{}, opts ...Option) error {
+	_, err := r.do(ctx, resty.MethodPut, url, body, result, opts)
+	return err
 }
 
-func (r *RestyClient) Post(url string, body interface{}, result interface{}) error {
-	resp, err := r.client.R().
-		SetBody(body).
-		SetResult(result).
-		Post(url)
+func (r *RestyClient) Patch(ctx context.Context, url string, body interface{}, result interface{}, opts ...Option) error {
+	_, err := r.do(ctx, resty.MethodPatch, url, body, result, opts)
+	return err
+}
 
-	if err != nil {
-		return err
+// Delete treats a connector that is already gone as deleted
+func (r *RestyClient) Delete(ctx context.Context, url string, opts ...Option) error {
+	resp, err := r.do(ctx, resty.MethodDelete, url, nil, nil, opts)
+	if err != nil && resp != nil && resp.StatusCode() == 404 {
+		return nil
 	}
+	return err
+}
 
-	if resp.IsError() {
-		return fmt.Errorf("HTTP error: %d - %s", resp.StatusCode(), resp.String())
+// do sends the request under the call's policy. Every attempt gets its own
+// timeout, ctx bounds the whole call including the waits between retries.
+func (r *RestyClient) do(ctx context.Context, method, url string, body, result interface{}, opts []Option) (resp *resty.Response, err error) {
+	policy := newPolicy(opts)
+
+	ctx, span := startSpan(ctx, method, url)
+	defer func() { tracing.End(span, err) }()
+
+	wait := policy.RetryWait
+	for attempt := 0; ; attempt++ {
+		resp, err = r.attempt(ctx, policy.Timeout, method, url, body, result)
+		if resp != nil && resp.RawResponse != nil {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode()))
+		}
+		span.SetAttributes(semconv.HTTPRequestResendCount(attempt))
+
+		if err == nil || attempt >= policy.Retries || !retryable(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+
+		r.logger.Warn("Retrying Kafka Connect call",
+			zap.String("method", method),
+			zap.String("url", url),
+			zap.Int("attempt", attempt+1),
+			zap.String("wait", wait.String()),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
 	}
-
-	return nil
 }
 
-func (r *RestyClient) Put(url string, body interface{}, result interface{}) error {
-	resp, err := r.client.R().
-		SetBody(body).
-		SetResult(result).
-		Put(url)
+func (r *RestyClient) attempt(ctx context.Context, timeout time.Duration, method, url string, body, result interface{}) (*resty.Response, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
-	if err != nil {
-		return err
+	req := r.request(ctx)
+	if body != nil {
+		req.SetBody(body)
+	}
+	if result != nil {
+		req.SetResult(result)
 	}
 
+	resp, err := req.Execute(method, url)
+	if err != nil {
+		return resp, &TransportError{Err: err}
+	}
 	if resp.IsError() {
-		return fmt.Errorf("HTTP error: %d - %s", resp.StatusCode(), resp.String())
+		return resp, &ResponseError{StatusCode: resp.StatusCode(), Body: resp.String()}
 	}
-
-	return nil
+	return resp, nil
 }
 
-func (r *RestyClient) Delete(url string) error {
-	resp, err := r.client.R().Delete(url)
-	if err != nil {
-		return err
+// retryable reports whether another attempt may succeed: connection errors,
+// timeouts, 5xx and the 409 Kafka Connect answers while it rebalances or its
+// config is stale. A 409 for a connector that already exists is final.
+func retryable(resp *resty.Response, err error) bool {
+	if resp == nil || resp.RawResponse == nil {
+		return true
 	}
-
-	if resp.IsError() && resp.StatusCode() != 404 {
-		return fmt.Errorf("HTTP error: %d - %s", resp.StatusCode(), resp.String())
+	status := resp.StatusCode()
+	if status >= 500 {
+		return true
 	}
+	return status == 409 && !strings.Contains(strings.ToLower(resp.String()), "already exists")
+}
+
+// request binds the call to ctx and passes its trace context on to Kafka Connect
+func (r *RestyClient) request(ctx context.Context) *resty.Request {
+	req := r.client.R().SetContext(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return req
+}
 
-	return nil
+// startSpan starts a client span for one Kafka Connect call, retries included
+func startSpan(ctx context.Context, method, url string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "kafka-connect "+method+" "+metrics.Endpoint(url),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.URLFull(url),
+		),
+	)
 }
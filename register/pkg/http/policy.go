@@ -0,0 +1,43 @@
+package http
+
+import "time"
+
+// Policy bounds a single HTTPClient call
+type Policy struct {
+	Timeout   time.Duration // per attempt, 0 leaves only the caller's context deadline
+	Retries   int           // attempts after the first one
+	RetryWait time.Duration // doubled after every retry
+}
+
+// DefaultPolicy applies to calls without options
+var DefaultPolicy = Policy{
+	Timeout:   30 * time.Second,
+	Retries:   3,
+	RetryWait: 5 * time.Second,
+}
+
+type Option func(*Policy)
+
+// WithPolicy replaces the whole policy of a call
+func WithPolicy(policy Policy) Option {
+	return func(p *Policy) { *p = policy }
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *Policy) { p.Timeout = timeout }
+}
+
+func WithRetries(retries int, wait time.Duration) Option {
+	return func(p *Policy) {
+		p.Retries = retries
+		p.RetryWait = wait
+	}
+}
+
+func newPolicy(opts []Option) Policy {
+	policy := DefaultPolicy
+	for _, opt := range opts {
+		opt(&policy)
+	}
+	return policy
+}
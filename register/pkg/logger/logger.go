@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"register/pkg/redact"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -32,12 +34,14 @@ type ZapLogger struct {
 	logger *zap.Logger
 }
 
-func NewZapLogger() Logger {
+func NewZapLogger(policy *redact.Policy) Logger {
 	config := zap.NewProductionConfig()
 	config.EncoderConfig.TimeKey = "timestamp"
 	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
-	logger, _ := config.Build()
+	logger, _ := config.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newRedactingCore(core, policy)
+	}))
 
 	return &ZapLogger{logger: logger}
 }
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"register/pkg/redact"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactingCore masks sensitive fields before they reach the encoder
+type redactingCore struct {
+	zapcore.Core
+	policy *redact.Policy
+}
+
+func newRedactingCore(core zapcore.Core, policy *redact.Policy) zapcore.Core {
+	return &redactingCore{Core: core, policy: policy}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redactFields(fields)), policy: c.policy}
+}
+
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.policy.Text(ent.Message)
+	return c.Core.Write(ent, c.redactFields(fields))
+}
+
+func (c *redactingCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = c.redactField(f)
+	}
+	return redacted
+}
+
+func (c *redactingCore) redactField(f zapcore.Field) zapcore.Field {
+	if c.policy.IsSensitive(f.Key) {
+		return zap.String(f.Key, redact.Mask)
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		f.String = c.policy.Text(f.String)
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return zap.String(f.Key, c.policy.Text(err.Error()))
+		}
+	case zapcore.ReflectType:
+		switch v := f.Interface.(type) {
+		case map[string]string:
+			return zap.Any(f.Key, c.policy.Map(v))
+		case map[string]interface{}:
+			return zap.Any(f.Key, c.policy.Interface(v))
+		case string:
+			return zap.String(f.Key, c.policy.Text(v))
+		}
+	}
+	return f
+}
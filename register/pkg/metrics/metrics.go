@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "cdc_registration"
+
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of API requests by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	connectRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "connect_request_duration_seconds",
+		Help:      "Duration of Kafka Connect REST calls by method, endpoint and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "endpoint", "status"})
+
+	connectRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "connect_request_errors_total",
+		Help:      "Failed Kafka Connect REST calls by method, endpoint and reason (4xx, 5xx or transport).",
+	}, []string{"method", "endpoint", "reason"})
+
+	connectors = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "connectors",
+		Help:      "Connectors in Kafka Connect by state.",
+	}, []string{"state"})
+
+	tasks = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "connector_tasks",
+		Help:      "Connector tasks in Kafka Connect by state.",
+	}, []string{"state"})
+)
+
+// Handler serves the default registry in the Prometheus text format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// ObserveRequest records an API request. route is the gin route template, so
+// connector names never become label values.
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// ObserveConnectCall records a Kafka Connect call that got a response
+func ObserveConnectCall(method, rawURL string, status int, duration time.Duration) {
+	endpoint := Endpoint(rawURL)
+	connectRequestDuration.WithLabelValues(method, endpoint, strconv.Itoa(status)).Observe(duration.Seconds())
+	if status >= 400 {
+		connectRequestErrors.WithLabelValues(method, endpoint, strconv.Itoa(status/100)+"xx").Inc()
+	}
+}
+
+// ObserveConnectError records a Kafka Connect call that failed without a response
+func ObserveConnectError(method, rawURL string) {
+	connectRequestErrors.WithLabelValues(method, Endpoint(rawURL), "transport").Inc()
+}
+
+// SetConnectorStates replaces the connector and task gauges with the given counts by state
+func SetConnectorStates(connectorStates, taskStates map[string]int) {
+	connectors.Reset()
+	for state, n := range connectorStates {
+		connectors.WithLabelValues(state).Set(float64(n))
+	}
+	tasks.Reset()
+	for state, n := range taskStates {
+		tasks.WithLabelValues(state).Set(float64(n))
+	}
+}
+
+// Endpoint turns a Kafka Connect URL into its path template, e.g.
+// /connectors/orders/tasks/0/restart becomes /connectors/:name/tasks/:id/restart
+func Endpoint(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "unknown"
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 1; i < len(segments); i++ {
+		switch segments[i-1] {
+		case "connectors":
+			segments[i] = ":name"
+		case "tasks":
+			segments[i] = ":id"
+		case "connector-plugins":
+			segments[i] = ":class"
+		case "loggers":
+			segments[i] = ":logger"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,130 @@
+package rbac
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"register/pkg/auth"
+	"register/pkg/logger"
+	"register/pkg/redact"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(policy *Policy) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	log := logger.NewZapLogger(redact.NewPolicy())
+	authenticator := auth.NewAPIKeyAuthenticator(map[string]string{
+		"viewer-key":  "ci",
+		"gateway-key": "api-gateway",
+	})
+	authz := NewAuthorizer(policy, nil, log)
+
+	r := gin.New()
+	r.Use(auth.Middleware(authenticator, log))
+	r.DELETE("/api/connectors/:name", authz.Require(Write), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestRoleHeaderOnlyTrustedFromProxies(t *testing.T) {
+	policy := &Policy{
+		RoleHeader:     "X-CDC-Role",
+		TrustedProxies: []string{"sub:api-gateway", "cn:gateway.example.com"},
+		Roles:          DefaultRoles,
+		DefaultRole:    "viewer",
+	}
+	router := newTestRouter(policy)
+
+	tests := []struct {
+		name   string
+		apiKey string
+		cert   string // client certificate common name
+		role   string
+		want   int
+	}{
+		{name: "api key without header", apiKey: "viewer-key", want: http.StatusForbidden},
+		{name: "api key raising its role", apiKey: "viewer-key", role: "admin", want: http.StatusForbidden},
+		{name: "trusted proxy principal", apiKey: "gateway-key", role: "admin", want: http.StatusOK},
+		{name: "trusted proxy certificate", apiKey: "viewer-key", cert: "gateway.example.com", role: "admin", want: http.StatusOK},
+		{name: "untrusted certificate", apiKey: "viewer-key", cert: "laptop.example.com", role: "admin", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", "/api/connectors/orders", nil)
+			req.Header.Set("X-API-Key", tt.apiKey)
+			if tt.role != "" {
+				req.Header.Set(policy.RoleHeader, tt.role)
+			}
+			if tt.cert != "" {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: tt.cert}}}}
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPrincipalsAndCertificatesHaveSeparateBindings(t *testing.T) {
+	policy := &Policy{
+		Roles: DefaultRoles,
+		Bindings: []Binding{
+			{Subject: "cn:ci", Role: "admin"},
+			{Subject: "sub:api-gateway", Role: "admin"},
+		},
+	}
+	router := newTestRouter(policy)
+
+	tests := []struct {
+		name   string
+		apiKey string
+		cert   string
+		want   int
+	}{
+		{name: "principal named like a certificate binding", apiKey: "viewer-key", want: http.StatusForbidden},
+		{name: "certificate named like a principal binding", apiKey: "viewer-key", cert: "api-gateway", want: http.StatusForbidden},
+		{name: "certificate binding", apiKey: "viewer-key", cert: "ci", want: http.StatusOK},
+		{name: "principal binding", apiKey: "gateway-key", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", "/api/connectors/orders", nil)
+			req.Header.Set("X-API-Key", tt.apiKey)
+			if tt.cert != "" {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: tt.cert}}}}
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLoadPolicyRequiresSubjectKinds(t *testing.T) {
+	for _, policy := range []string{
+		"bindings:\n  - subject: platform-team\n    role: admin\n",
+		"role_header: X-CDC-Role\ntrusted_proxies: [api-gateway]\n",
+	} {
+		file := filepath.Join(t.TempDir(), "policy.yaml")
+		if err := os.WriteFile(file, []byte(policy), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicy(file); err == nil || !strings.Contains(err.Error(), "must start with sub: or cn:") {
+			t.Errorf("%q: got %v, want a subject kind error", policy, err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package rbac
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Permission string
+
+const (
+	Read    Permission = "connectors:read"    // list connectors, read status and reports
+	Operate Permission = "connectors:operate" // pause, resume, restart, validate
+	Write   Permission = "connectors:write"   // register, update and delete
+)
+
+// Subjects of bindings and trusted proxies name their kind, so an authenticated
+// principal never picks up the binding of a client certificate or the reverse
+const (
+	PrincipalPrefix   = "sub:" // sub:<authenticated principal>
+	CertificatePrefix = "cn:"  // cn:<client certificate common name>
+)
+
+// Binding assigns a role to a subject, either an authenticated principal
+// (sub:name) or the common name of a client certificate (cn:name). Bindings
+// with topic prefixes only apply to connectors whose topic prefix matches one of them.
+type Binding struct {
+	Subject       string   `yaml:"subject" json:"subject"`
+	Role          string   `yaml:"role" json:"role"`
+	TopicPrefixes []string `yaml:"topic_prefixes,omitempty" json:"topic_prefixes,omitempty"`
+}
+
+type Policy struct {
+	// RoleHeader names a header carrying the caller's role, only accepted from TrustedProxies
+	RoleHeader string `yaml:"role_header,omitempty" json:"role_header,omitempty"`
+	// TopicPrefixHeader names a header scoping RoleHeader to comma separated topic prefixes
+	TopicPrefixHeader string `yaml:"topic_prefix_header,omitempty" json:"topic_prefix_header,omitempty"`
+	// TrustedProxies are the principals or client certificate common names
+	// allowed to set the role headers
+	TrustedProxies []string                `yaml:"trusted_proxies,omitempty" json:"trusted_proxies,omitempty"`
+	Roles          map[string][]Permission `yaml:"roles" json:"roles"`
+	Bindings       []Binding               `yaml:"bindings" json:"bindings"`
+	// DefaultRole applies to callers without a binding, empty denies them
+	DefaultRole string `yaml:"default_role,omitempty" json:"default_role,omitempty"`
+}
+
+// DefaultRoles are used when the policy file defines no roles
+var DefaultRoles = map[string][]Permission{
+	"viewer":   {Read},
+	"operator": {Read, Operate},
+	"admin":    {Read, Operate, Write},
+}
+
+// LoadPolicy reads a YAML or JSON policy file
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file %s: %w", file, err)
+	}
+
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", file, err)
+	}
+	if len(policy.Roles) == 0 {
+		policy.Roles = DefaultRoles
+	}
+
+	for _, b := range policy.Bindings {
+		if err := checkSubject(b.Subject); err != nil {
+			return nil, fmt.Errorf("binding: %w", err)
+		}
+		if _, ok := policy.Roles[b.Role]; !ok {
+			return nil, fmt.Errorf("binding for %s references unknown role %s", b.Subject, b.Role)
+		}
+	}
+	if policy.DefaultRole != "" {
+		if _, ok := policy.Roles[policy.DefaultRole]; !ok {
+			return nil, fmt.Errorf("default role %s is not defined", policy.DefaultRole)
+		}
+	}
+	if policy.RoleHeader != "" && len(policy.TrustedProxies) == 0 {
+		return nil, fmt.Errorf("role_header %s requires trusted_proxies", policy.RoleHeader)
+	}
+	for _, proxy := range policy.TrustedProxies {
+		if err := checkSubject(proxy); err != nil {
+			return nil, fmt.Errorf("trusted_proxies: %w", err)
+		}
+	}
+	return &policy, nil
+}
+
+func checkSubject(subject string) error {
+	if !strings.HasPrefix(subject, PrincipalPrefix) && !strings.HasPrefix(subject, CertificatePrefix) {
+		return fmt.Errorf("subject %q must start with %s or %s", subject, PrincipalPrefix, CertificatePrefix)
+	}
+	return nil
+}
+
+// Grant is the role resolved for a request
+type Grant struct {
+	Subject       string       `json:"subject"`
+	Role          string       `json:"role"`
+	Source        string       `json:"source"` // header, certificate, principal or default
+	Permissions   []Permission `json:"permissions"`
+	TopicPrefixes []string     `json:"topic_prefixes,omitempty"`
+}
+
+func (g *Grant) Allows(permission Permission) bool {
+	for _, p := range g.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsTopicPrefix reports whether the grant covers connectors with topicPrefix.
+// Prefix patterns use path.Match syntax, so "team-a*" matches "team-a.orders".
+// A nil grant, authorization disabled, covers every prefix.
+func (g *Grant) AllowsTopicPrefix(topicPrefix string) bool {
+	if g == nil || len(g.TopicPrefixes) == 0 {
+		return true
+	}
+	for _, pattern := range g.TopicPrefixes {
+		if ok, _ := path.Match(pattern, topicPrefix); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve finds the caller's role from the role header of a trusted proxy, the
+// client certificate or the authenticated subject, in that order. Role headers
+// from any other caller are rejected.
+func (p *Policy) Resolve(r *http.Request, subject string) (*Grant, error) {
+	if p.RoleHeader != "" {
+		if role := r.Header.Get(p.RoleHeader); role != "" {
+			if !p.trustsProxy(r, subject) {
+				return nil, fmt.Errorf("%s is not a trusted proxy and cannot set %s", subject, p.RoleHeader)
+			}
+			grant, err := p.grant(subject, role, "header")
+			if err != nil {
+				return nil, err
+			}
+			if p.TopicPrefixHeader != "" {
+				for _, prefix := range strings.Split(r.Header.Get(p.TopicPrefixHeader), ",") {
+					if prefix = strings.TrimSpace(prefix); prefix != "" {
+						grant.TopicPrefixes = append(grant.TopicPrefixes, prefix)
+					}
+				}
+			}
+			return grant, nil
+		}
+	}
+
+	if cert := clientCertificate(r); cert != nil {
+		if b, ok := p.binding(CertificatePrefix + cert.Subject.CommonName); ok {
+			return p.bindingGrant(b, "certificate")
+		}
+	}
+
+	if b, ok := p.binding(PrincipalPrefix + subject); ok {
+		return p.bindingGrant(b, "principal")
+	}
+
+	if p.DefaultRole != "" {
+		return p.grant(subject, p.DefaultRole, "default")
+	}
+	return nil, fmt.Errorf("no role is bound to %s", subject)
+}
+
+// trustsProxy reports whether the caller authenticated, by principal or
+// verified client certificate, as one of the trusted proxies
+func (p *Policy) trustsProxy(r *http.Request, subject string) bool {
+	for _, proxy := range p.TrustedProxies {
+		if proxy == PrincipalPrefix+subject {
+			return true
+		}
+		if cert := clientCertificate(r); cert != nil && proxy == CertificatePrefix+cert.Subject.CommonName {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) binding(subject string) (Binding, bool) {
+	for _, b := range p.Bindings {
+		if b.Subject == subject {
+			return b, true
+		}
+	}
+	return Binding{}, false
+}
+
+func (p *Policy) bindingGrant(b Binding, source string) (*Grant, error) {
+	grant, err := p.grant(b.Subject, b.Role, source)
+	if err != nil {
+		return nil, err
+	}
+	grant.TopicPrefixes = b.TopicPrefixes
+	return grant, nil
+}
+
+func (p *Policy) grant(subject, role, source string) (*Grant, error) {
+	permissions, ok := p.Roles[role]
+	if !ok {
+		return nil, fmt.Errorf("unknown role %s", role)
+	}
+	return &Grant{
+		Subject:     subject,
+		Role:        role,
+		Source:      source,
+		Permissions: permissions,
+	}, nil
+}
+
+func clientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return r.TLS.PeerCertificates[0]
+}
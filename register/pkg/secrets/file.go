@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type fileStore struct {
+	dir       string
+	mountPath string
+
+	mu sync.Mutex // serializes the read-modify-write of Put
+}
+
+// NewFileStore writes secrets to dir, which Kafka Connect workers see at mountPath
+func NewFileStore(dir, mountPath string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create secrets directory %s: %w", dir, err)
+	}
+	if mountPath == "" {
+		mountPath = dir
+	}
+	return &fileStore{
+		dir:       dir,
+		mountPath: mountPath,
+	}, nil
+}
+
+func (s *fileStore) Put(connectorName string, values map[string]string) error {
+	if err := checkName(connectorName); err != nil {
+		return err
+	}
+	file := filepath.Join(s.dir, fileName(connectorName))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := make(map[string]string)
+	data, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read secrets of connector %s: %w", connectorName, err)
+	}
+	if err == nil {
+		existing = decodeProperties(data)
+	}
+	for k, v := range values {
+		existing[k] = v
+	}
+
+	// Write to a temp file first so workers never read a partial file
+	if err := writeFileAtomic(file, encodeProperties(existing)); err != nil {
+		return fmt.Errorf("failed to write secrets of connector %s: %w", connectorName, err)
+	}
+	return nil
+}
+
+func (s *fileStore) Delete(connectorName string) error {
+	if err := checkName(connectorName); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := os.Remove(filepath.Join(s.dir, fileName(connectorName)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete secrets of connector %s: %w", connectorName, err)
+	}
+	return nil
+}
+
+func (s *fileStore) Placeholder(connectorName, key string) string {
+	return filePlaceholder(s.mountPath, connectorName, key)
+}
+
+// writeFileAtomic writes data to a unique temp file next to file, readable by
+// the owner only, and renames it over file
+func writeFileAtomic(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly after the rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
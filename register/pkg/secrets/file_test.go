@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestFileStoreRejectsUnsafeNames(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFileStore(filepath.Join(root, "secrets"), "/secrets")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	outside := filepath.Join(root, "outside.properties")
+	if err := os.WriteFile(outside, []byte("keep=me\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"../outside", "a/b", "..", ""} {
+		if err := store.Put(name, map[string]string{"database.password": "s3cret"}); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Put(%q): got %v, want ErrInvalidName", name, err)
+		}
+		if err := store.Delete(name); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("Delete(%q): got %v, want ErrInvalidName", name, err)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Fatalf("file outside the secrets directory was removed: %v", err)
+	}
+	if err := store.Put("team-a.orders", map[string]string{"database.password": "s3cret"}); err != nil {
+		t.Fatalf("Put of a valid name: %v", err)
+	}
+}
+
+func TestFileStoreConcurrentPutsKeepEveryKey(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, "/secrets")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	const keys = 20
+	var wg sync.WaitGroup
+	for i := 0; i < keys; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := store.Put("orders", map[string]string{fmt.Sprintf("key.%d", i): "s3cret"}); err != nil {
+				t.Errorf("Put: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(filepath.Join(dir, "orders.properties"))
+	if err != nil {
+		t.Fatalf("failed to read secrets: %v", err)
+	}
+	if got := decodeProperties(data); len(got) != keys {
+		t.Fatalf("got %d keys, want %d: %v", len(got), keys, got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("temp files were left behind: %v", entries)
+	}
+}
@@ -0,0 +1,179 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	registerhttp "register/pkg/http"
+	"strings"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+type kubernetesStore struct {
+	client     *resty.Client
+	namespace  string
+	secretName string
+	mountPath  string
+
+	mu sync.Mutex // serializes the read-modify-write of Put
+}
+
+// NewKubernetesStore keeps the secrets of all connectors in one Kubernetes Secret,
+// one properties file per connector. Kafka Connect mounts the Secret at mountPath.
+// It uses the in-cluster service account, which needs get, create and patch on secrets.
+func NewKubernetesStore(namespace, secretName, mountPath string) (Store, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("kubernetes secret store requires running in a cluster")
+	}
+
+	if namespace == "" {
+		ns, err := os.ReadFile(serviceAccountDir + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service account namespace: %w", err)
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+
+	client := registerhttp.NewWithBaseURL("https://" + net.JoinHostPort(host, port))
+	client.SetRootCertificate(serviceAccountDir + "/ca.crt")
+	// Service account tokens are rotated, so read the token for every request
+	client.OnBeforeRequest(func(c *resty.Client, r *resty.Request) error {
+		token, err := os.ReadFile(serviceAccountDir + "/token")
+		if err != nil {
+			return fmt.Errorf("failed to read service account token: %w", err)
+		}
+		r.SetAuthToken(strings.TrimSpace(string(token)))
+		return nil
+	})
+
+	return &kubernetesStore{
+		client:     client,
+		namespace:  namespace,
+		secretName: secretName,
+		mountPath:  mountPath,
+	}, nil
+}
+
+type kubernetesSecret struct {
+	Data map[string]string `json:"data"`
+}
+
+func (s *kubernetesStore) Put(connectorName string, values map[string]string) error {
+	if err := checkName(connectorName); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret, found, err := s.getSecret()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]string)
+	if encoded, ok := secret.Data[fileName(connectorName)]; ok {
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("failed to decode secrets of connector %s: %w", connectorName, err)
+		}
+		existing = decodeProperties(data)
+	}
+	for k, v := range values {
+		existing[k] = v
+	}
+
+	data := map[string]interface{}{
+		fileName(connectorName): base64.StdEncoding.EncodeToString(encodeProperties(existing)),
+	}
+	if !found {
+		return s.createSecret(data)
+	}
+	return s.patchSecret(data)
+}
+
+func (s *kubernetesStore) Delete(connectorName string) error {
+	if err := checkName(connectorName); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, found, err := s.getSecret()
+	if err != nil || !found {
+		return err
+	}
+	// A null value removes the key with a JSON merge patch
+	return s.patchSecret(map[string]interface{}{fileName(connectorName): nil})
+}
+
+func (s *kubernetesStore) Placeholder(connectorName, key string) string {
+	return filePlaceholder(s.mountPath, connectorName, key)
+}
+
+func (s *kubernetesStore) secretsPath() string {
+	return fmt.Sprintf("/api/v1/namespaces/%s/secrets", s.namespace)
+}
+
+func (s *kubernetesStore) getSecret() (*kubernetesSecret, bool, error) {
+	var secret kubernetesSecret
+	resp, err := s.client.R().
+		SetResult(&secret).
+		Get(s.secretsPath() + "/" + s.secretName)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get secret %s: %w", s.secretName, err)
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		return &kubernetesSecret{}, false, nil
+	}
+	if resp.IsError() {
+		return nil, false, fmt.Errorf("failed to get secret %s: HTTP error: %d - %s", s.secretName, resp.StatusCode(), resp.String())
+	}
+	return &secret, true, nil
+}
+
+func (s *kubernetesStore) createSecret(data map[string]interface{}) error {
+	body := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":      s.secretName,
+			"namespace": s.namespace,
+		},
+		"type": "Opaque",
+		"data": data,
+	}
+
+	resp, err := s.client.R().
+		SetBody(body).
+		Post(s.secretsPath())
+	if err != nil {
+		return fmt.Errorf("failed to create secret %s: %w", s.secretName, err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("failed to create secret %s: HTTP error: %d - %s", s.secretName, resp.StatusCode(), resp.String())
+	}
+	return nil
+}
+
+func (s *kubernetesStore) patchSecret(data map[string]interface{}) error {
+	resp, err := s.client.R().
+		SetHeader("Content-Type", "application/merge-patch+json").
+		SetBody(map[string]interface{}{"data": data}).
+		Patch(s.secretsPath() + "/" + s.secretName)
+	if err != nil {
+		return fmt.Errorf("failed to patch secret %s: %w", s.secretName, err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("failed to patch secret %s: HTTP error: %d - %s", s.secretName, resp.StatusCode(), resp.String())
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Store keeps connector credentials outside of Kafka Connect configs. Secrets of a
+// connector are written as a properties file that Kafka's FileConfigProvider reads
+// on the Connect workers, referenced from the config through placeholders.
+type Store interface {
+	// Put merges values into the connector's secrets
+	Put(connectorName string, values map[string]string) error
+	Delete(connectorName string) error
+	// Placeholder returns the config value Kafka Connect resolves to the secret
+	Placeholder(connectorName, key string) string
+}
+
+// IsPlaceholder reports whether a config value is a config provider reference
+func IsPlaceholder(value string) bool {
+	return strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}")
+}
+
+var ErrInvalidName = errors.New("invalid connector name")
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// checkName rejects connector names that could escape the secrets directory
+func checkName(connectorName string) error {
+	if !namePattern.MatchString(connectorName) || strings.Contains(connectorName, "..") {
+		return fmt.Errorf("%w: %q", ErrInvalidName, connectorName)
+	}
+	return nil
+}
+
+func fileName(connectorName string) string {
+	return connectorName + ".properties"
+}
+
+// filePlaceholder references a key of the connector's properties file under mountPath
+func filePlaceholder(mountPath, connectorName, key string) string {
+	return fmt.Sprintf("${file:%s:%s}", path.Join(mountPath, fileName(connectorName)), key)
+}
+
+// encodeProperties renders values in java.util.Properties format, sorted by key
+func encodeProperties(values map[string]string) []byte {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(escapeProperty(k, true))
+		b.WriteString("=")
+		b.WriteString(escapeProperty(values[k], false))
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// decodeProperties parses files written by encodeProperties
+func decodeProperties(data []byte) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		key, value := splitProperty(line)
+		values[unescapeProperty(key)] = unescapeProperty(value)
+	}
+	return values
+}
+
+var propertyEscapes = map[rune]string{
+	'\\': `\\`,
+	'\n': `\n`,
+	'\r': `\r`,
+	'\t': `\t`,
+	'\f': `\f`,
+}
+
+func escapeProperty(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		if escaped, ok := propertyEscapes[r]; ok {
+			b.WriteString(escaped)
+			continue
+		}
+		switch {
+		case r == ' ' && (isKey || i == 0):
+			b.WriteString(`\ `)
+		case r == '=' || r == ':' || r == '#' || r == '!':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func unescapeProperty(s string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if !escaped {
+			if r == '\\' {
+				escaped = true
+				continue
+			}
+			b.WriteRune(r)
+			continue
+		}
+		escaped = false
+		switch r {
+		case 'n':
+			b.WriteRune('\n')
+		case 'r':
+			b.WriteRune('\r')
+		case 't':
+			b.WriteRune('\t')
+		case 'f':
+			b.WriteRune('\f')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// splitProperty splits a line at the first unescaped '='
+func splitProperty(line string) (string, string) {
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '=':
+			return line[:i], line[i+1:]
+		}
+	}
+	return line, ""
+}
@@ -0,0 +1,174 @@
+package tenant
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"register/pkg/apierror"
+	"register/pkg/auth"
+	"register/pkg/logger"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// Key of the caller's tenant in the gin context
+const ContextKey = "tenant"
+
+// Separator between the tenant and connector names or topic prefixes
+const Separator = "."
+
+type Tenant struct {
+	Name          string   `yaml:"name" json:"name"`
+	Members       []string `yaml:"members" json:"members"`               // principal subjects belonging to the tenant
+	MaxConnectors int      `yaml:"max_connectors" json:"max_connectors"` // 0 means unlimited
+	MaxTasks      int      `yaml:"max_tasks" json:"max_tasks"`           // 0 means unlimited
+}
+
+type Directory struct {
+	// Header names a header carrying the caller's tenant. It is only honoured
+	// for members of that tenant and for the subjects listed in Proxies.
+	Header  string   `yaml:"header,omitempty" json:"header,omitempty"`
+	Proxies []string `yaml:"proxies,omitempty" json:"proxies,omitempty"` // subjects trusted to act for any tenant
+	Tenants []Tenant `yaml:"tenants" json:"tenants"`
+}
+
+// LoadDirectory reads a YAML or JSON tenants file
+func LoadDirectory(file string) (*Directory, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file %s: %w", file, err)
+	}
+
+	var dir Directory
+	if err := yaml.Unmarshal(data, &dir); err != nil {
+		return nil, fmt.Errorf("failed to parse tenants file %s: %w", file, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range dir.Tenants {
+		if t.Name == "" || strings.Contains(t.Name, Separator) {
+			return nil, fmt.Errorf("invalid tenant name %q", t.Name)
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("duplicate tenant %s", t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return &dir, nil
+}
+
+// Lookup returns the tenant with name or nil
+func (d *Directory) Lookup(name string) *Tenant {
+	if d == nil {
+		return nil
+	}
+	for i := range d.Tenants {
+		if d.Tenants[i].Name == name {
+			return &d.Tenants[i]
+		}
+	}
+	return nil
+}
+
+// Resolve finds the caller's tenant from the header or the subject's membership.
+// A header naming a tenant the subject does not belong to is rejected unless
+// the subject is a trusted proxy.
+func (d *Directory) Resolve(r *http.Request, subject string) (*Tenant, error) {
+	if d.Header != "" {
+		if name := r.Header.Get(d.Header); name != "" {
+			t := d.Lookup(name)
+			if t == nil {
+				return nil, fmt.Errorf("unknown tenant %s", name)
+			}
+			if !t.HasMember(subject) && !contains(d.Proxies, subject) {
+				return nil, fmt.Errorf("%s is not a member of tenant %s", subject, name)
+			}
+			return t, nil
+		}
+	}
+
+	for i := range d.Tenants {
+		if d.Tenants[i].HasMember(subject) {
+			return &d.Tenants[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s does not belong to any tenant", subject)
+}
+
+// HasMember reports whether subject belongs to the tenant
+func (t *Tenant) HasMember(subject string) bool {
+	return contains(t.Members, subject)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Qualify prefixes name with the tenant unless it already is
+func Qualify(tenant, name string) string {
+	if tenant == "" || Owns(tenant, name) {
+		return name
+	}
+	return tenant + Separator + name
+}
+
+// Owns reports whether a qualified name belongs to tenant
+func Owns(tenant, name string) bool {
+	return strings.HasPrefix(name, tenant+Separator)
+}
+
+// Middleware resolves the caller's tenant and qualifies the :name route
+// parameter, so handlers and later middleware only see namespaced names.
+// A nil directory disables multi-tenancy.
+func Middleware(d *Directory, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d == nil {
+			c.Next()
+			return
+		}
+
+		t, err := d.Resolve(c.Request, auth.SubjectFrom(c))
+		if err != nil {
+			log.Warn("Tenant resolution failed",
+				logger.String("principal", auth.SubjectFrom(c)),
+				logger.Error(err),
+			)
+			apierror.Abort(c, http.StatusForbidden, apierror.Error{Code: apierror.CodeForbidden, Message: err.Error()})
+			return
+		}
+
+		for i, p := range c.Params {
+			if p.Key == "name" {
+				c.Params[i].Value = Qualify(t.Name, p.Value)
+			}
+		}
+
+		c.Set(ContextKey, t)
+		c.Next()
+	}
+}
+
+// From returns the caller's tenant, nil when multi-tenancy is disabled
+func From(c *gin.Context) *Tenant {
+	value, ok := c.Get(ContextKey)
+	if !ok {
+		return nil
+	}
+	t, _ := value.(*Tenant)
+	return t
+}
+
+// NameFrom returns the caller's tenant name, empty when multi-tenancy is disabled
+func NameFrom(c *gin.Context) string {
+	if t := From(c); t != nil {
+		return t.Name
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package tenant
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveHeader(t *testing.T) {
+	dir := &Directory{
+		Header:  "X-CDC-Tenant",
+		Proxies: []string{"api-gateway"},
+		Tenants: []Tenant{
+			{Name: "team-a", Members: []string{"alice", "shared-ci"}},
+			{Name: "team-b", Members: []string{"bob", "shared-ci"}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		subject string
+		header  string
+		want    string // empty expects an error
+	}{
+		{name: "membership", subject: "alice", want: "team-a"},
+		{name: "header of own tenant", subject: "shared-ci", header: "team-b", want: "team-b"},
+		{name: "header of other tenant", subject: "alice", header: "team-b"},
+		{name: "proxy acts for any tenant", subject: "api-gateway", header: "team-b", want: "team-b"},
+		{name: "unknown tenant", subject: "api-gateway", header: "team-c"},
+		{name: "no tenant", subject: "mallory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/connectors", nil)
+			if tt.header != "" {
+				r.Header.Set(dir.Header, tt.header)
+			}
+
+			got, err := dir.Resolve(r, tt.subject)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("resolved %s, want an error", got.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolve: %v", err)
+			}
+			if got.Name != tt.want {
+				t.Fatalf("resolved %s, want %s", got.Name, tt.want)
+			}
+		})
+	}
+}
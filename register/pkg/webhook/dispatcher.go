@@ -0,0 +1,260 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"register/pkg/logger"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Delivery is one attempt to deliver an event to a subscriber
+type Delivery struct {
+	EventID    string `json:"event_id"`
+	EventType  string `json:"event_type"`
+	Connector  string `json:"connector"`
+	URL        string `json:"url"`
+	Attempt    int    `json:"attempt"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	Duration   string `json:"duration"`
+	Timestamp  string `json:"timestamp"`
+}
+
+type Dispatcher interface {
+	// Start runs the delivery worker until ctx is done, pending retries are dropped
+	Start(ctx context.Context)
+	// Dispatch queues event for every matching subscriber
+	Dispatch(event Event)
+	// Deliveries returns the delivery log, newest first
+	Deliveries() []Delivery
+}
+
+type dispatcher struct {
+	cfg    Config
+	client *http.Client
+	log    logger.Logger
+	queue  chan *pending
+
+	mu         sync.Mutex
+	deliveries []Delivery
+}
+
+// pending is an event waiting to be delivered to one subscriber
+type pending struct {
+	subscriber Subscriber
+	event      Event
+	body       []byte
+	attempt    int
+	backoff    time.Duration
+	due        time.Time
+}
+
+func NewDispatcher(cfg Config, log logger.Logger) Dispatcher {
+	cfg.setDefaults()
+	return &dispatcher{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		log:    log,
+		queue:  make(chan *pending, cfg.QueueSize),
+	}
+}
+
+func (d *dispatcher) Dispatch(event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		d.log.Error("Failed to encode webhook event", logger.Error(err))
+		return
+	}
+
+	for _, s := range d.cfg.Subscribers {
+		if !s.Matches(event) {
+			continue
+		}
+		select {
+		case d.queue <- &pending{subscriber: s, event: event, body: body, backoff: d.cfg.InitialBackoff}:
+		default:
+			d.log.Warn("Webhook queue is full, dropping delivery",
+				logger.String("url", s.URL),
+				logger.String("event_id", event.ID),
+			)
+		}
+	}
+}
+
+// Start delivers queued events one at a time and keeps failed ones until
+// their backoff is over, so a slow subscriber delays the others instead of
+// piling up goroutines
+func (d *dispatcher) Start(ctx context.Context) {
+	d.log.Info("Starting webhook dispatcher", logger.Int("subscribers", len(d.cfg.Subscribers)))
+
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	var retries []*pending
+	for {
+		var wait <-chan time.Time
+		if next := nextDue(retries); next != nil {
+			timer.Reset(time.Until(next.due))
+			wait = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			d.log.Info("Stopping webhook dispatcher", logger.Int("dropped", len(retries)+len(d.queue)))
+			return
+		case p := <-d.queue:
+			retries = d.deliver(ctx, p, retries)
+		case <-wait:
+		}
+
+		var due []*pending
+		due, retries = splitDue(retries, time.Now())
+		for _, p := range due {
+			if ctx.Err() != nil {
+				break
+			}
+			retries = d.deliver(ctx, p, retries)
+		}
+	}
+}
+
+// deliver sends one attempt and returns retries with p added back when the
+// attempt failed and p has attempts left
+func (d *dispatcher) deliver(ctx context.Context, p *pending, retries []*pending) []*pending {
+	p.attempt++
+	delivery := d.send(ctx, p.subscriber, p.event, p.body)
+	delivery.Attempt = p.attempt
+	d.record(delivery)
+	if delivery.Success || ctx.Err() != nil {
+		return retries
+	}
+
+	if p.attempt >= d.cfg.MaxAttempts {
+		d.log.Warn("Giving up on webhook delivery",
+			logger.String("url", p.subscriber.URL),
+			logger.String("event_id", p.event.ID),
+			logger.Int("attempts", p.attempt),
+		)
+		return retries
+	}
+	p.due = time.Now().Add(p.backoff)
+	p.backoff *= 2
+	return append(retries, p)
+}
+
+func (d *dispatcher) send(ctx context.Context, s Subscriber, event Event, body []byte) (delivery Delivery) {
+	now := time.Now()
+	delivery = Delivery{
+		EventID:   event.ID,
+		EventType: event.Type,
+		Connector: event.Connector,
+		URL:       s.URL,
+		Timestamp: now.Format(time.RFC3339),
+	}
+	defer func() { delivery.Duration = time.Since(now).String() }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, event.ID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(s.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !delivery.Success {
+		delivery.Error = fmt.Sprintf("subscriber answered %d", resp.StatusCode)
+	}
+	return delivery
+}
+
+func (d *dispatcher) record(delivery Delivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deliveries = append(d.deliveries, delivery)
+	if len(d.deliveries) > d.cfg.LogSize {
+		d.deliveries = d.deliveries[len(d.deliveries)-d.cfg.LogSize:]
+	}
+}
+
+func (d *dispatcher) Deliveries() []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deliveries := make([]Delivery, 0, len(d.deliveries))
+	for i := len(d.deliveries) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, d.deliveries[i])
+	}
+	return deliveries
+}
+
+// nextDue returns the retry whose backoff ends first, nil without retries
+func nextDue(retries []*pending) *pending {
+	var next *pending
+	for _, p := range retries {
+		if next == nil || p.due.Before(next.due) {
+			next = p
+		}
+	}
+	return next
+}
+
+// splitDue separates the retries whose backoff has ended at now from the others
+func splitDue(retries []*pending, now time.Time) (due, waiting []*pending) {
+	for _, p := range retries {
+		if p.due.After(now) {
+			waiting = append(waiting, p)
+		} else {
+			due = append(due, p)
+		}
+	}
+	return due, waiting
+}
+
+// Sign returns the signature header value for a delivery sent at timestamp,
+// receivers recompute it with their secret and compare in constant time
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a delivery against
+// body. Deliveries sent more than SignatureTolerance away from now are
+// rejected, so a captured delivery cannot be replayed later.
+func Verify(secret, timestamp string, body []byte, signature string, now time.Time) bool {
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(sent, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,243 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"register/pkg/logger"
+	"register/pkg/redact"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// subscriber records the deliveries it receives and answers with the queued statuses, then 204
+type subscriber struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	received []received
+}
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+func newSubscriber(t *testing.T, statuses ...int) *subscriber {
+	t.Helper()
+	s := &subscriber{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.received = append(s.received, received{header: r.Header.Clone(), body: body})
+		status := http.StatusNoContent
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		s.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *subscriber) deliveries() []received {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]received(nil), s.received...)
+}
+
+// waitFor polls the delivery log until it has n deliveries
+func waitFor(t *testing.T, d Dispatcher, n int) []Delivery {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		deliveries := d.Deliveries()
+		if len(deliveries) >= n {
+			return deliveries
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d deliveries, want %d", len(deliveries), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// newTestDispatcher runs a dispatcher until the test ends
+func newTestDispatcher(t *testing.T, subscribers ...Subscriber) Dispatcher {
+	t.Helper()
+	d := NewDispatcher(Config{
+		Subscribers:    subscribers,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		Timeout:        time.Second,
+	}, logger.NewZapLogger(redact.NewPolicy()))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go d.Start(ctx)
+	return d
+}
+
+func failedEvent() Event {
+	taskID := 0
+	return Event{ID: "evt-1", Type: TaskStateChanged, Connector: "orders", TaskID: &taskID, From: "RUNNING", To: "FAILED"}
+}
+
+func TestDispatchSignsDeliveries(t *testing.T) {
+	sub := newSubscriber(t)
+	d := newTestDispatcher(t, Subscriber{URL: sub.URL, Secret: "shh"})
+
+	d.Dispatch(failedEvent())
+	deliveries := waitFor(t, d, 1)
+	if !deliveries[0].Success || deliveries[0].StatusCode != http.StatusNoContent {
+		t.Fatalf("got delivery %+v, want a successful one", deliveries[0])
+	}
+
+	got := sub.deliveries()[0]
+	if !Verify("shh", got.header.Get(TimestampHeader), got.body, got.header.Get(SignatureHeader), time.Now()) {
+		t.Fatalf("signature %q does not match the timestamp and body", got.header.Get(SignatureHeader))
+	}
+	if got.header.Get(EventHeader) != TaskStateChanged || got.header.Get(DeliveryHeader) != "evt-1" {
+		t.Fatalf("got headers %v", got.header)
+	}
+	var event Event
+	if err := json.Unmarshal(got.body, &event); err != nil {
+		t.Fatalf("failed to decode event: %v", err)
+	}
+	if event.Connector != "orders" || event.To != "FAILED" || event.TaskID == nil || *event.TaskID != 0 {
+		t.Fatalf("got event %+v", event)
+	}
+}
+
+func TestDispatchRetriesFailedDeliveries(t *testing.T) {
+	sub := newSubscriber(t, http.StatusInternalServerError, http.StatusBadGateway)
+	d := newTestDispatcher(t, Subscriber{URL: sub.URL, Secret: "shh"})
+
+	d.Dispatch(failedEvent())
+	deliveries := waitFor(t, d, 3)
+	if !deliveries[0].Success || deliveries[0].Attempt != 3 {
+		t.Fatalf("got last delivery %+v, want the third attempt to succeed", deliveries[0])
+	}
+	if deliveries[2].Success || deliveries[2].StatusCode != http.StatusInternalServerError {
+		t.Fatalf("got first delivery %+v, want it failed with 500", deliveries[2])
+	}
+}
+
+func TestDispatchGivesUpAfterMaxAttempts(t *testing.T) {
+	sub := newSubscriber(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	d := newTestDispatcher(t, Subscriber{URL: sub.URL, Secret: "shh"})
+
+	d.Dispatch(failedEvent())
+	waitFor(t, d, 3)
+	time.Sleep(20 * time.Millisecond)
+	if got := len(sub.deliveries()); got != 3 {
+		t.Fatalf("subscriber got %d deliveries, want 3", got)
+	}
+}
+
+func TestDispatchFiltersSubscribers(t *testing.T) {
+	tests := []struct {
+		name       string
+		subscriber Subscriber
+		want       bool
+	}{
+		{name: "all connectors", subscriber: Subscriber{}, want: true},
+		{name: "matching pattern", subscriber: Subscriber{Connectors: []string{"ord*"}}, want: true},
+		{name: "other pattern", subscriber: Subscriber{Connectors: []string{"payments-*"}}, want: false},
+		{name: "matching state", subscriber: Subscriber{States: []string{"FAILED"}}, want: true},
+		{name: "other state", subscriber: Subscriber{States: []string{"PAUSED"}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := newSubscriber(t)
+			tt.subscriber.URL = sub.URL
+			tt.subscriber.Secret = "shh"
+			d := newTestDispatcher(t, tt.subscriber)
+
+			d.Dispatch(failedEvent())
+			if tt.want {
+				waitFor(t, d, 1)
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+			if got := len(sub.deliveries()); got != 0 {
+				t.Fatalf("subscriber got %d deliveries, want none", got)
+			}
+		})
+	}
+}
+
+func TestDispatchStopsRetriesOnShutdown(t *testing.T) {
+	sub := newSubscriber(t, http.StatusInternalServerError, http.StatusInternalServerError)
+	d := NewDispatcher(Config{
+		Subscribers:    []Subscriber{{URL: sub.URL, Secret: "shh"}},
+		MaxAttempts:    3,
+		InitialBackoff: 50 * time.Millisecond,
+		Timeout:        time.Second,
+	}, logger.NewZapLogger(redact.NewPolicy()))
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		d.Start(ctx)
+		close(stopped)
+	}()
+
+	d.Dispatch(failedEvent())
+	waitFor(t, d, 1)
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("dispatcher did not stop while a retry was waiting")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if got := len(sub.deliveries()); got != 1 {
+		t.Fatalf("subscriber got %d deliveries after shutdown, want only the first attempt", got)
+	}
+}
+
+func TestVerifyRejectsReplays(t *testing.T) {
+	body := []byte(`{"id":"evt-1"}`)
+	sent := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(sent.Unix(), 10)
+	signature := Sign("shh", timestamp, body)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		body      []byte
+		now       time.Time
+		want      bool
+	}{
+		{name: "fresh delivery", timestamp: timestamp, body: body, now: sent.Add(time.Minute), want: true},
+		{name: "replayed after the tolerance", timestamp: timestamp, body: body, now: sent.Add(SignatureTolerance + time.Second)},
+		{name: "timestamp from the future", timestamp: timestamp, body: body, now: sent.Add(-SignatureTolerance - time.Second)},
+		{name: "timestamp swapped for a fresh one", timestamp: strconv.FormatInt(sent.Unix()+600, 10), body: body, now: sent.Add(10 * time.Minute)},
+		{name: "tampered body", timestamp: timestamp, body: []byte(`{"id":"evt-2"}`), now: sent},
+		{name: "malformed timestamp", timestamp: "yesterday", body: body, now: sent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify("shh", tt.timestamp, tt.body, signature, tt.now); got != tt.want {
+				t.Fatalf("Verify: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigRequiresSecrets(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "webhooks.yaml")
+	if err := os.WriteFile(file, []byte("subscribers:\n  - url: https://alerts.example.com/cdc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(file); err == nil {
+		t.Fatal("loaded a subscriber without secret, want an error")
+	}
+}
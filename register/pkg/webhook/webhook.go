@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Event types
+const (
+	ConnectorStateChanged = "connector.state_changed"
+	TaskStateChanged      = "task.state_changed"
+	TaskHealExhausted     = "task.heal_exhausted" // auto-heal gave up restarting a failed task
+)
+
+// Headers sent with every delivery
+const (
+	SignatureHeader = "X-CDC-Signature" // sha256=<hex HMAC-SHA256 of timestamp + "." + body keyed with the subscriber secret>
+	TimestampHeader = "X-CDC-Timestamp" // unix seconds when the attempt was sent
+	EventHeader     = "X-CDC-Event"
+	DeliveryHeader  = "X-CDC-Delivery"
+)
+
+// SignatureTolerance is how far a delivery timestamp may be from the
+// receiver's clock before Verify rejects it as a replay
+const SignatureTolerance = 5 * time.Minute
+
+// Event is the JSON body of a webhook
+type Event struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Connector string `json:"connector"`
+	TaskID    *int   `json:"task_id,omitempty"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	WorkerID  string `json:"worker_id,omitempty"`
+	Trace     string `json:"trace,omitempty"`
+	Timestamp string `json:"timestamp"`
+}
+
+// Subscriber receives events for the connectors matching its patterns, or for
+// all connectors when it has none
+type Subscriber struct {
+	URL        string   `yaml:"url" json:"url"`
+	Secret     string   `yaml:"secret" json:"-"`                                  // required, signs every delivery
+	Connectors []string `yaml:"connectors,omitempty" json:"connectors,omitempty"` // glob patterns
+	States     []string `yaml:"states,omitempty" json:"states,omitempty"`         // only transitions to these states, empty for all
+}
+
+// Matches reports whether the subscriber wants event
+func (s Subscriber) Matches(event Event) bool {
+	if len(s.States) > 0 && !contains(s.States, event.To) {
+		return false
+	}
+	if len(s.Connectors) == 0 {
+		return true
+	}
+	for _, pattern := range s.Connectors {
+		if ok, _ := path.Match(pattern, event.Connector); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type Config struct {
+	Subscribers    []Subscriber  `yaml:"subscribers" json:"subscribers"`
+	MaxAttempts    int           `yaml:"max_attempts,omitempty" json:"max_attempts,omitempty"`
+	InitialBackoff time.Duration `yaml:"initial_backoff,omitempty" json:"initial_backoff,omitempty"` // doubled after every failed attempt
+	Timeout        time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	LogSize        int           `yaml:"log_size,omitempty" json:"log_size,omitempty"`     // deliveries kept in the delivery log
+	QueueSize      int           `yaml:"queue_size,omitempty" json:"queue_size,omitempty"` // deliveries waiting for the worker, more are dropped
+}
+
+// LoadConfig reads a YAML or JSON webhooks file and fills in defaults
+func LoadConfig(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhooks file %s: %w", file, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse webhooks file %s: %w", file, err)
+	}
+	for _, s := range cfg.Subscribers {
+		if s.URL == "" {
+			return nil, fmt.Errorf("webhook subscriber without url in %s", file)
+		}
+		if s.Secret == "" {
+			return nil, fmt.Errorf("webhook subscriber %s without secret in %s", s.URL, file)
+		}
+	}
+	cfg.setDefaults()
+	return &cfg, nil
+}
+
+func (c *Config) setDefaults() {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 5
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = time.Second
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.LogSize <= 0 {
+		c.LogSize = 500
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 1000
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package preflight
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"register/models"
+	"register/pkg/logger"
+	"register/pkg/tracing"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrUnsupportedDatabase = errors.New("no pre-flight checks for database type")
+
+// OpenFunc opens a connection to the source database, sql.Open by default.
+// Tests can swap it for a sqlmock backed connection.
+type OpenFunc func(driverName, dataSourceName string) (*sql.DB, error)
+
+type Checker interface {
+	Check(ctx context.Context, req models.RegisterConnectorRequest) (*models.PreflightReport, error)
+}
+
+type checker struct {
+	checkers map[models.Database]Checker
+}
+
+// NewChecker returns a Checker dispatching on the request's database type
+func NewChecker(log logger.Logger) Checker {
+	return &checker{
+		checkers: map[models.Database]Checker{
+			models.MYSQL:     NewMySQLChecker(sql.Open, log),
+			models.POSTGRES:  NewPostgresChecker(sql.Open, log),
+			models.SQLSERVER: NewSQLServerChecker(sql.Open, log),
+			models.ORACLE:    NewOracleChecker(sql.Open, log),
+		},
+	}
+}
+
+func (c *checker) Check(ctx context.Context, req models.RegisterConnectorRequest) (result *models.PreflightReport, err error) {
+	ctx, span := tracing.Start(ctx, "preflight "+string(req.DatabaseType), trace.WithAttributes(
+		attribute.String("db.system", string(req.DatabaseType)),
+		attribute.String("server.address", req.DatabaseHost),
+		attribute.String("db.namespace", req.DatabaseName),
+	))
+	defer func() {
+		if result != nil {
+			span.SetAttributes(attribute.Bool("preflight.passed", result.Passed), attribute.Int("preflight.checks", len(result.Checks)))
+		}
+		tracing.End(span, err)
+	}()
+
+	databaseType, _ := models.ParseDatabase(string(req.DatabaseType))
+	dbChecker, ok := c.checkers[databaseType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDatabase, req.DatabaseType)
+	}
+	return dbChecker.Check(ctx, req)
+}
+
+// report collects checks and tracks whether all of them passed
+type report struct {
+	*models.PreflightReport
+}
+
+func newReport(req models.RegisterConnectorRequest) *report {
+	return &report{&models.PreflightReport{
+		DatabaseType: req.DatabaseType,
+		DatabaseHost: req.DatabaseHost,
+		DatabaseName: req.DatabaseName,
+		Passed:       true,
+		Checks:       []models.PreflightCheck{},
+		CheckedAt:    time.Now().Format(time.RFC3339),
+	}}
+}
+
+func (r *report) add(check models.PreflightCheck) {
+	if !check.Passed {
+		r.Passed = false
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// expect adds a check comparing a server variable against its required value
+func (r *report) expect(name, expected, actual, message string) {
+	check := models.PreflightCheck{
+		Name:     name,
+		Passed:   strings.EqualFold(expected, actual),
+		Expected: expected,
+		Actual:   actual,
+	}
+	if !check.Passed {
+		check.Message = message
+	}
+	r.add(check)
+}
+
+// splitTable splits a possibly qualified table name, falling back to the default schema
+func splitTable(table, defaultSchema string) (string, string) {
+	if i := strings.Index(table, "."); i >= 0 {
+		return table[:i], table[i+1:]
+	}
+	return defaultSchema, table
+}
@@ -0,0 +1,64 @@
+package preflight
+
+import (
+	"database/sql"
+	"register/models"
+	"register/pkg/logger"
+	"register/pkg/redact"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// mockOpen returns an OpenFunc handing out a sqlmock connection that answers
+// the first ping with pingErr
+func mockOpen(t *testing.T, pingErr error) (OpenFunc, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	mock.ExpectPing().WillReturnError(pingErr)
+	open := func(driverName, dataSourceName string) (*sql.DB, error) {
+		return db, nil
+	}
+	return open, mock
+}
+
+func testLogger() logger.Logger {
+	return logger.NewZapLogger(redact.NewPolicy())
+}
+
+// failedChecks returns the names of the checks that did not pass
+func failedChecks(report *models.PreflightReport) []string {
+	var failed []string
+	for _, check := range report.Checks {
+		if !check.Passed {
+			failed = append(failed, check.Name)
+		}
+	}
+	return failed
+}
+
+func expectFailed(t *testing.T, report *models.PreflightReport, want ...string) {
+	t.Helper()
+
+	failed := failedChecks(report)
+	if report.Passed != (len(want) == 0) {
+		t.Fatalf("report passed = %v with failed checks %v", report.Passed, failed)
+	}
+	if len(failed) != len(want) {
+		t.Fatalf("failed checks %v, want %v", failed, want)
+	}
+	for i := range want {
+		if failed[i] != want[i] {
+			t.Fatalf("failed checks %v, want %v", failed, want)
+		}
+	}
+}
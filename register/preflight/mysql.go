@@ -0,0 +1,212 @@
+package preflight
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"register/models"
+	"register/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
+)
+
+// Privileges Debezium needs, matching the grants in k8s/mysql.yaml
+var mysqlRequiredPrivileges = []string{
+	"SELECT",
+	"RELOAD",
+	"SHOW DATABASES",
+	"REPLICATION SLAVE",
+	"REPLICATION CLIENT",
+}
+
+type mysqlChecker struct {
+	open OpenFunc
+	log  logger.Logger
+}
+
+func NewMySQLChecker(open OpenFunc, log logger.Logger) Checker {
+	return &mysqlChecker{
+		open: open,
+		log:  log,
+	}
+}
+
+func (c *mysqlChecker) Check(ctx context.Context, req models.RegisterConnectorRequest) (*models.PreflightReport, error) {
+	report := newReport(req)
+
+	cfg := mysql.NewConfig()
+	cfg.User = req.Username
+	cfg.Passwd = req.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(req.DatabaseHost, strconv.Itoa(req.DatabasePort))
+	cfg.DBName = req.DatabaseName
+	cfg.Timeout = 5 * time.Second
+
+	db, err := c.open("mysql", cfg.FormatDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mysql connection: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		report.add(models.PreflightCheck{
+			Name:    "connection",
+			Message: fmt.Sprintf("cannot connect as %s: %v", req.Username, err),
+		})
+		return report.PreflightReport, nil
+	}
+	report.add(models.PreflightCheck{Name: "connection", Passed: true})
+
+	if err := c.checkVariables(ctx, db, report); err != nil {
+		return nil, err
+	}
+	if err := c.checkGrants(ctx, db, req, report); err != nil {
+		return nil, err
+	}
+	if err := c.checkTables(ctx, db, req, report); err != nil {
+		return nil, err
+	}
+
+	c.log.Info("MySQL pre-flight finished",
+		zap.String("host", req.DatabaseHost),
+		zap.Bool("passed", report.Passed),
+	)
+	return report.PreflightReport, nil
+}
+
+func (c *mysqlChecker) checkVariables(ctx context.Context, db *sql.DB, report *report) error {
+	rows, err := db.QueryContext(ctx, "SHOW GLOBAL VARIABLES WHERE Variable_name IN ('log_bin', 'binlog_format', 'binlog_row_image', 'gtid_mode')")
+	if err != nil {
+		return fmt.Errorf("failed to read mysql server variables: %w", err)
+	}
+	defer rows.Close()
+
+	variables := make(map[string]string)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return fmt.Errorf("failed to read mysql server variables: %w", err)
+		}
+		variables[strings.ToLower(name)] = value
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read mysql server variables: %w", err)
+	}
+
+	report.expect("log_bin", "ON", variables["log_bin"], "enable binary logging with --log-bin")
+	report.expect("binlog_format", "ROW", variables["binlog_format"], "set binlog_format=ROW")
+	report.expect("binlog_row_image", "FULL", variables["binlog_row_image"], "set binlog_row_image=FULL")
+	report.expect("gtid_mode", "ON", variables["gtid_mode"], "set gtid_mode=ON and enforce_gtid_consistency=ON")
+	return nil
+}
+
+func (c *mysqlChecker) checkGrants(ctx context.Context, db *sql.DB, req models.RegisterConnectorRequest, report *report) error {
+	rows, err := db.QueryContext(ctx, "SHOW GRANTS FOR CURRENT_USER()")
+	if err != nil {
+		return fmt.Errorf("failed to read mysql grants: %w", err)
+	}
+	defer rows.Close()
+
+	granted := make(map[string]bool)
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			return fmt.Errorf("failed to read mysql grants: %w", err)
+		}
+		privileges, object, ok := parseGrant(grant)
+		if !ok {
+			continue
+		}
+		global := object == "*.*"
+		onDatabase := strings.EqualFold(strings.Trim(strings.TrimSuffix(object, ".*"), "`"), req.DatabaseName)
+		for _, privilege := range privileges {
+			switch {
+			case global:
+				granted[privilege] = true
+			case onDatabase && (privilege == "SELECT" || privilege == "ALL PRIVILEGES"):
+				// Database level SELECT is enough for the snapshot
+				granted["SELECT"] = true
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read mysql grants: %w", err)
+	}
+
+	for _, privilege := range mysqlRequiredPrivileges {
+		passed := granted[privilege] || granted["ALL PRIVILEGES"]
+		check := models.PreflightCheck{
+			Name:     "grant:" + strings.ToLower(strings.ReplaceAll(privilege, " ", "_")),
+			Passed:   passed,
+			Expected: privilege,
+		}
+		if !passed {
+			check.Message = fmt.Sprintf("GRANT %s ON *.* TO '%s'@'%%'", privilege, req.Username)
+		}
+		report.add(check)
+	}
+	return nil
+}
+
+func (c *mysqlChecker) checkTables(ctx context.Context, db *sql.DB, req models.RegisterConnectorRequest, report *report) error {
+	for _, table := range req.Tables {
+		schema, name := splitTable(table, req.DatabaseName)
+		checkName := fmt.Sprintf("table:%s.%s", schema, name)
+
+		var exists int
+		err := db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = ? AND table_name = ?",
+			schema, name,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to look up table %s.%s: %w", schema, name, err)
+		}
+		if exists == 0 {
+			report.add(models.PreflightCheck{
+				Name:    checkName,
+				Message: fmt.Sprintf("table %s.%s does not exist", schema, name),
+			})
+			continue
+		}
+
+		var primaryKeys int
+		err = db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM information_schema.table_constraints WHERE table_schema = ? AND table_name = ? AND constraint_type = 'PRIMARY KEY'",
+			schema, name,
+		).Scan(&primaryKeys)
+		if err != nil {
+			return fmt.Errorf("failed to look up primary key of %s.%s: %w", schema, name, err)
+		}
+
+		check := models.PreflightCheck{Name: checkName, Passed: primaryKeys > 0}
+		if !check.Passed {
+			check.Message = fmt.Sprintf("table %s.%s has no primary key", schema, name)
+		}
+		report.add(check)
+	}
+	return nil
+}
+
+// parseGrant splits "GRANT SELECT, RELOAD ON *.* TO `user`@`%`" into its privileges and object
+func parseGrant(grant string) ([]string, string, bool) {
+	upper := strings.ToUpper(grant)
+	if !strings.HasPrefix(upper, "GRANT ") {
+		return nil, "", false
+	}
+	on := strings.Index(upper, " ON ")
+	to := strings.Index(upper, " TO ")
+	if on < 0 || to < on {
+		return nil, "", false
+	}
+
+	var privileges []string
+	for _, privilege := range strings.Split(upper[len("GRANT "):on], ",") {
+		privileges = append(privileges, strings.TrimSpace(privilege))
+	}
+	return privileges, strings.TrimSpace(grant[on+len(" ON ") : to]), true
+}
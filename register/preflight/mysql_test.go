@@ -0,0 +1,99 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"register/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func mysqlRequest() models.RegisterConnectorRequest {
+	return models.RegisterConnectorRequest{
+		DatabaseType: models.MYSQL,
+		DatabaseHost: "mysql",
+		DatabasePort: 3306,
+		DatabaseName: "inventory",
+		Username:     "debezium",
+		Password:     "dbz",
+		Tables:       []string{"customers"},
+	}
+}
+
+func expectMySQLVariables(mock sqlmock.Sqlmock, logBin string) {
+	mock.ExpectQuery(`SHOW GLOBAL VARIABLES`).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+		AddRow("binlog_format", "ROW").
+		AddRow("binlog_row_image", "FULL").
+		AddRow("gtid_mode", "ON").
+		AddRow("log_bin", logBin))
+}
+
+func expectMySQLGrants(mock sqlmock.Sqlmock, grants ...string) {
+	rows := sqlmock.NewRows([]string{"Grants for debezium@%"})
+	for _, grant := range grants {
+		rows.AddRow(grant)
+	}
+	mock.ExpectQuery(`SHOW GRANTS FOR CURRENT_USER\(\)`).WillReturnRows(rows)
+}
+
+func expectMySQLTable(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`FROM information_schema.tables`).WithArgs("inventory", "customers").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`FROM information_schema.table_constraints`).WithArgs("inventory", "customers").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+}
+
+func TestMySQLCheckPasses(t *testing.T) {
+	open, mock := mockOpen(t, nil)
+	expectMySQLVariables(mock, "ON")
+	expectMySQLGrants(mock,
+		"GRANT SELECT, RELOAD, SHOW DATABASES, REPLICATION SLAVE, REPLICATION CLIENT ON *.* TO `debezium`@`%`",
+	)
+	expectMySQLTable(mock)
+
+	report, err := NewMySQLChecker(open, testLogger()).Check(context.Background(), mysqlRequest())
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	expectFailed(t, report)
+}
+
+func TestMySQLCheckReportsMissingPrivileges(t *testing.T) {
+	open, mock := mockOpen(t, nil)
+	expectMySQLVariables(mock, "ON")
+	expectMySQLGrants(mock,
+		"GRANT RELOAD, SHOW DATABASES ON *.* TO `debezium`@`%`",
+		"GRANT SELECT ON `inventory`.* TO `debezium`@`%`",
+	)
+	expectMySQLTable(mock)
+
+	report, err := NewMySQLChecker(open, testLogger()).Check(context.Background(), mysqlRequest())
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	expectFailed(t, report, "grant:replication_slave", "grant:replication_client")
+}
+
+func TestMySQLCheckReportsDisabledBinlog(t *testing.T) {
+	open, mock := mockOpen(t, nil)
+	expectMySQLVariables(mock, "OFF")
+	expectMySQLGrants(mock, "GRANT ALL PRIVILEGES ON *.* TO `debezium`@`%`")
+	expectMySQLTable(mock)
+
+	report, err := NewMySQLChecker(open, testLogger()).Check(context.Background(), mysqlRequest())
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	expectFailed(t, report, "log_bin")
+}
+
+func TestMySQLCheckReportsUnreachableDatabase(t *testing.T) {
+	open, _ := mockOpen(t, errors.New("dial tcp mysql:3306: connect: connection refused"))
+
+	report, err := NewMySQLChecker(open, testLogger()).Check(context.Background(), mysqlRequest())
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	expectFailed(t, report, "connection")
+}
@@ -0,0 +1,143 @@
+package preflight
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"register/models"
+	"register/pkg/logger"
+	"strings"
+
+	go_ora "github.com/sijms/go-ora/v2"
+	"go.uber.org/zap"
+)
+
+type oracleChecker struct {
+	open OpenFunc
+	log  logger.Logger
+}
+
+func NewOracleChecker(open OpenFunc, log logger.Logger) Checker {
+	return &oracleChecker{
+		open: open,
+		log:  log,
+	}
+}
+
+func (c *oracleChecker) Check(ctx context.Context, req models.RegisterConnectorRequest) (*models.PreflightReport, error) {
+	report := newReport(req)
+
+	// Captured tables live in the PDB when the database is multitenant
+	service := req.DatabaseName
+	if req.PDBName != "" {
+		service = req.PDBName
+	}
+	dsn := go_ora.BuildUrl(req.DatabaseHost, req.DatabasePort, service, req.Username, req.Password, map[string]string{
+		"CONNECTION TIMEOUT": "5",
+	})
+
+	db, err := c.open("oracle", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open oracle connection: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		report.add(models.PreflightCheck{
+			Name:    "connection",
+			Message: fmt.Sprintf("cannot connect as %s: %v", req.Username, err),
+		})
+		return report.PreflightReport, nil
+	}
+	report.add(models.PreflightCheck{Name: "connection", Passed: true})
+
+	allColumns, err := c.checkDatabase(ctx, db, report)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkTables(ctx, db, req, allColumns, report); err != nil {
+		return nil, err
+	}
+
+	c.log.Info("Oracle pre-flight finished",
+		zap.String("host", req.DatabaseHost),
+		zap.Bool("passed", report.Passed),
+	)
+	return report.PreflightReport, nil
+}
+
+// checkDatabase verifies ARCHIVELOG mode and minimal supplemental logging and
+// reports whether all columns are logged database wide
+func (c *oracleChecker) checkDatabase(ctx context.Context, db *sql.DB, report *report) (bool, error) {
+	var logMode, minimal, all string
+	err := db.QueryRowContext(ctx, "SELECT LOG_MODE, SUPPLEMENTAL_LOG_DATA_MIN, SUPPLEMENTAL_LOG_DATA_ALL FROM V$DATABASE").Scan(&logMode, &minimal, &all)
+	if err != nil {
+		return false, fmt.Errorf("failed to read V$DATABASE: %w", err)
+	}
+
+	report.expect("log_mode", "ARCHIVELOG", logMode,
+		"SHUTDOWN IMMEDIATE; STARTUP MOUNT; ALTER DATABASE ARCHIVELOG; ALTER DATABASE OPEN")
+
+	check := models.PreflightCheck{
+		Name:     "supplemental_log_data_min",
+		Passed:   minimal == "YES" || minimal == "IMPLICIT",
+		Expected: "YES",
+		Actual:   minimal,
+	}
+	if !check.Passed {
+		check.Message = "ALTER DATABASE ADD SUPPLEMENTAL LOG DATA"
+	}
+	report.add(check)
+
+	return all == "YES", nil
+}
+
+func (c *oracleChecker) checkTables(ctx context.Context, db *sql.DB, req models.RegisterConnectorRequest, allColumns bool, report *report) error {
+	defaultSchema := req.Schema
+	if defaultSchema == "" {
+		defaultSchema = req.Username
+	}
+
+	for _, table := range req.Tables {
+		schema, name := splitTable(table, defaultSchema)
+		schema, name = strings.ToUpper(schema), strings.ToUpper(name)
+		checkName := fmt.Sprintf("table:%s.%s", schema, name)
+
+		var exists int
+		err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM ALL_TABLES WHERE OWNER = :1 AND TABLE_NAME = :2", schema, name).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to look up table %s.%s: %w", schema, name, err)
+		}
+		if exists == 0 {
+			report.add(models.PreflightCheck{
+				Name:    checkName,
+				Message: fmt.Sprintf("table %s.%s does not exist or is not visible to %s", schema, name, req.Username),
+			})
+			continue
+		}
+
+		logged := allColumns
+		if !logged {
+			var groups int
+			err := db.QueryRowContext(ctx,
+				"SELECT COUNT(*) FROM ALL_LOG_GROUPS WHERE OWNER = :1 AND TABLE_NAME = :2 AND LOG_GROUP_TYPE = 'ALL COLUMN LOGGING'",
+				schema, name,
+			).Scan(&groups)
+			if err != nil {
+				return fmt.Errorf("failed to read supplemental logging of %s.%s: %w", schema, name, err)
+			}
+			logged = groups > 0
+		}
+
+		check := models.PreflightCheck{
+			Name:     checkName,
+			Passed:   logged,
+			Expected: "ALL COLUMN LOGGING",
+		}
+		if !check.Passed {
+			check.Message = fmt.Sprintf("ALTER TABLE %s.%s ADD SUPPLEMENTAL LOG DATA (ALL) COLUMNS", schema, name)
+		}
+		report.add(check)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package preflight
+
+import (
+	"context"
+	"register/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func oracleRequest() models.RegisterConnectorRequest {
+	return models.RegisterConnectorRequest{
+		DatabaseType: models.ORACLE,
+		DatabaseHost: "oracle",
+		DatabasePort: 1521,
+		DatabaseName: "ORCLCDB",
+		PDBName:      "ORCLPDB1",
+		Username:     "c##dbzuser",
+		Password:     "dbz",
+		Schema:       "inventory",
+		Tables:       []string{"customers"},
+	}
+}
+
+func TestOracleCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		logMode   string
+		minimal   string
+		all       string
+		logGroups int
+		failed    []string
+	}{
+		{name: "passes with database wide logging", logMode: "ARCHIVELOG", minimal: "YES", all: "YES"},
+		{name: "passes with a table log group", logMode: "ARCHIVELOG", minimal: "IMPLICIT", all: "NO", logGroups: 1},
+		{name: "archive log disabled", logMode: "NOARCHIVELOG", minimal: "YES", all: "YES", failed: []string{"log_mode"}},
+		{name: "supplemental logging disabled", logMode: "ARCHIVELOG", minimal: "NO", all: "NO", failed: []string{"supplemental_log_data_min", "table:INVENTORY.CUSTOMERS"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, mock := mockOpen(t, nil)
+			mock.ExpectQuery(`FROM V\$DATABASE`).WillReturnRows(
+				sqlmock.NewRows([]string{"LOG_MODE", "SUPPLEMENTAL_LOG_DATA_MIN", "SUPPLEMENTAL_LOG_DATA_ALL"}).AddRow(tt.logMode, tt.minimal, tt.all))
+			mock.ExpectQuery(`FROM ALL_TABLES`).WithArgs("INVENTORY", "CUSTOMERS").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			if tt.all != "YES" {
+				mock.ExpectQuery(`FROM ALL_LOG_GROUPS`).WithArgs("INVENTORY", "CUSTOMERS").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.logGroups))
+			}
+
+			report, err := NewOracleChecker(open, testLogger()).Check(context.Background(), oracleRequest())
+			if err != nil {
+				t.Fatalf("check: %v", err)
+			}
+			expectFailed(t, report, tt.failed...)
+		})
+	}
+}
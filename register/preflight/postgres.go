@@ -0,0 +1,166 @@
+package preflight
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"register/models"
+	"register/pkg/logger"
+	"strconv"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+)
+
+// Debezium's default publication name for the pgoutput plugin
+const postgresPublication = "dbz_publication"
+
+type postgresChecker struct {
+	open OpenFunc
+	log  logger.Logger
+}
+
+func NewPostgresChecker(open OpenFunc, log logger.Logger) Checker {
+	return &postgresChecker{
+		open: open,
+		log:  log,
+	}
+}
+
+func (c *postgresChecker) Check(ctx context.Context, req models.RegisterConnectorRequest) (*models.PreflightReport, error) {
+	report := newReport(req)
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(req.Username, req.Password),
+		Host:     net.JoinHostPort(req.DatabaseHost, strconv.Itoa(req.DatabasePort)),
+		Path:     req.DatabaseName,
+		RawQuery: "connect_timeout=5",
+	}
+
+	db, err := c.open("pgx", dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres connection: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		report.add(models.PreflightCheck{
+			Name:    "connection",
+			Message: fmt.Sprintf("cannot connect as %s: %v", req.Username, err),
+		})
+		return report.PreflightReport, nil
+	}
+	report.add(models.PreflightCheck{Name: "connection", Passed: true})
+
+	if err := c.checkSettings(ctx, db, report); err != nil {
+		return nil, err
+	}
+	superuser, err := c.checkRole(ctx, db, req, report)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkPublication(ctx, db, superuser, report); err != nil {
+		return nil, err
+	}
+	if err := c.checkTables(ctx, db, req, report); err != nil {
+		return nil, err
+	}
+
+	c.log.Info("PostgreSQL pre-flight finished",
+		zap.String("host", req.DatabaseHost),
+		zap.Bool("passed", report.Passed),
+	)
+	return report.PreflightReport, nil
+}
+
+func (c *postgresChecker) checkSettings(ctx context.Context, db *sql.DB, report *report) error {
+	var walLevel string
+	if err := db.QueryRowContext(ctx, "SHOW wal_level").Scan(&walLevel); err != nil {
+		return fmt.Errorf("failed to read wal_level: %w", err)
+	}
+	report.expect("wal_level", "logical", walLevel, "set wal_level=logical and restart the server")
+
+	var maxSlots, usedSlots int
+	if err := db.QueryRowContext(ctx, "SELECT setting::int FROM pg_settings WHERE name = 'max_replication_slots'").Scan(&maxSlots); err != nil {
+		return fmt.Errorf("failed to read max_replication_slots: %w", err)
+	}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pg_replication_slots").Scan(&usedSlots); err != nil {
+		return fmt.Errorf("failed to count replication slots: %w", err)
+	}
+
+	check := models.PreflightCheck{
+		Name:     "max_replication_slots",
+		Passed:   maxSlots > usedSlots,
+		Expected: fmt.Sprintf("more than %d", usedSlots),
+		Actual:   strconv.Itoa(maxSlots),
+	}
+	if !check.Passed {
+		check.Message = "increase max_replication_slots or drop unused replication slots"
+	}
+	report.add(check)
+	return nil
+}
+
+// checkRole verifies the REPLICATION attribute and reports whether the user is a superuser
+func (c *postgresChecker) checkRole(ctx context.Context, db *sql.DB, req models.RegisterConnectorRequest, report *report) (bool, error) {
+	var replication, superuser bool
+	err := db.QueryRowContext(ctx, "SELECT rolreplication, rolsuper FROM pg_roles WHERE rolname = current_user").Scan(&replication, &superuser)
+	if err != nil {
+		return false, fmt.Errorf("failed to read role attributes: %w", err)
+	}
+
+	check := models.PreflightCheck{
+		Name:     "role:replication",
+		Passed:   replication || superuser,
+		Expected: "REPLICATION",
+	}
+	if !check.Passed {
+		check.Message = fmt.Sprintf("ALTER ROLE %s WITH REPLICATION", req.Username)
+	}
+	report.add(check)
+	return superuser, nil
+}
+
+// checkPublication passes when the publication exists or the user may let Debezium create it
+func (c *postgresChecker) checkPublication(ctx context.Context, db *sql.DB, superuser bool, report *report) error {
+	var exists int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pg_publication WHERE pubname = $1", postgresPublication).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up publication: %w", err)
+	}
+
+	check := models.PreflightCheck{
+		Name:     "publication",
+		Passed:   exists > 0 || superuser,
+		Expected: postgresPublication,
+	}
+	if !check.Passed {
+		check.Message = fmt.Sprintf("CREATE PUBLICATION %s FOR ALL TABLES, or grant the user rights to create it", postgresPublication)
+	}
+	report.add(check)
+	return nil
+}
+
+func (c *postgresChecker) checkTables(ctx context.Context, db *sql.DB, req models.RegisterConnectorRequest, report *report) error {
+	for _, table := range req.Tables {
+		schema, name := splitTable(table, "public")
+
+		var exists int
+		err := db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = $1 AND table_name = $2",
+			schema, name,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to look up table %s.%s: %w", schema, name, err)
+		}
+
+		check := models.PreflightCheck{Name: fmt.Sprintf("table:%s.%s", schema, name), Passed: exists > 0}
+		if !check.Passed {
+			check.Message = fmt.Sprintf("table %s.%s does not exist", schema, name)
+		}
+		report.add(check)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package preflight
+
+import (
+	"context"
+	"register/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func postgresRequest() models.RegisterConnectorRequest {
+	return models.RegisterConnectorRequest{
+		DatabaseType: models.POSTGRES,
+		DatabaseHost: "postgres",
+		DatabasePort: 5432,
+		DatabaseName: "orders",
+		Username:     "debezium",
+		Password:     "dbz",
+		Tables:       []string{"public.orders"},
+	}
+}
+
+type postgresState struct {
+	walLevel    string
+	replication bool
+	superuser   bool
+	publication int
+}
+
+func expectPostgres(mock sqlmock.Sqlmock, state postgresState) {
+	mock.ExpectQuery(`SHOW wal_level`).WillReturnRows(sqlmock.NewRows([]string{"wal_level"}).AddRow(state.walLevel))
+	mock.ExpectQuery(`max_replication_slots`).WillReturnRows(sqlmock.NewRows([]string{"setting"}).AddRow(10))
+	mock.ExpectQuery(`FROM pg_replication_slots`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`FROM pg_roles`).
+		WillReturnRows(sqlmock.NewRows([]string{"rolreplication", "rolsuper"}).AddRow(state.replication, state.superuser))
+	mock.ExpectQuery(`FROM pg_publication`).WithArgs(postgresPublication).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(state.publication))
+	mock.ExpectQuery(`FROM information_schema.tables`).WithArgs("public", "orders").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+}
+
+func TestPostgresCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  postgresState
+		failed []string
+	}{
+		{name: "passes", state: postgresState{walLevel: "logical", replication: true, publication: 1}},
+		{name: "superuser may create the publication", state: postgresState{walLevel: "logical", superuser: true}},
+		{name: "wal_level is not logical", state: postgresState{walLevel: "replica", replication: true, publication: 1}, failed: []string{"wal_level"}},
+		{name: "missing replication role", state: postgresState{walLevel: "logical", publication: 1}, failed: []string{"role:replication"}},
+		{name: "missing publication", state: postgresState{walLevel: "logical", replication: true}, failed: []string{"publication"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, mock := mockOpen(t, nil)
+			expectPostgres(mock, tt.state)
+
+			report, err := NewPostgresChecker(open, testLogger()).Check(context.Background(), postgresRequest())
+			if err != nil {
+				t.Fatalf("check: %v", err)
+			}
+			expectFailed(t, report, tt.failed...)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package preflight
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"register/models"
+	"register/pkg/logger"
+	"strconv"
+
+	_ "github.com/microsoft/go-mssqldb"
+	"go.uber.org/zap"
+)
+
+type sqlServerChecker struct {
+	open OpenFunc
+	log  logger.Logger
+}
+
+func NewSQLServerChecker(open OpenFunc, log logger.Logger) Checker {
+	return &sqlServerChecker{
+		open: open,
+		log:  log,
+	}
+}
+
+func (c *sqlServerChecker) Check(ctx context.Context, req models.RegisterConnectorRequest) (*models.PreflightReport, error) {
+	report := newReport(req)
+
+	query := url.Values{}
+	query.Set("database", req.DatabaseName)
+	query.Set("encrypt", strconv.FormatBool(req.Encrypt))
+	query.Set("TrustServerCertificate", strconv.FormatBool(req.TrustServerCertificate))
+	query.Set("dial timeout", "5")
+
+	dsn := url.URL{
+		Scheme:   "sqlserver",
+		User:     url.UserPassword(req.Username, req.Password),
+		Host:     net.JoinHostPort(req.DatabaseHost, strconv.Itoa(req.DatabasePort)),
+		RawQuery: query.Encode(),
+	}
+
+	db, err := c.open("sqlserver", dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlserver connection: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		report.add(models.PreflightCheck{
+			Name:    "connection",
+			Message: fmt.Sprintf("cannot connect as %s: %v", req.Username, err),
+		})
+		return report.PreflightReport, nil
+	}
+	report.add(models.PreflightCheck{Name: "connection", Passed: true})
+
+	if err := c.checkDatabaseCDC(ctx, db, req, report); err != nil {
+		return nil, err
+	}
+	if err := c.checkTables(ctx, db, req, report); err != nil {
+		return nil, err
+	}
+
+	c.log.Info("SQL Server pre-flight finished",
+		zap.String("host", req.DatabaseHost),
+		zap.Bool("passed", report.Passed),
+	)
+	return report.PreflightReport, nil
+}
+
+func (c *sqlServerChecker) checkDatabaseCDC(ctx context.Context, db *sql.DB, req models.RegisterConnectorRequest, report *report) error {
+	var enabled bool
+	err := db.QueryRowContext(ctx, "SELECT is_cdc_enabled FROM sys.databases WHERE name = @p1", req.DatabaseName).Scan(&enabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		report.add(models.PreflightCheck{
+			Name:    "database_cdc",
+			Message: fmt.Sprintf("database %s does not exist", req.DatabaseName),
+		})
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read CDC state of database %s: %w", req.DatabaseName, err)
+	}
+
+	check := models.PreflightCheck{
+		Name:     "database_cdc",
+		Passed:   enabled,
+		Expected: "enabled",
+		Actual:   cdcState(enabled),
+	}
+	if !check.Passed {
+		check.Message = fmt.Sprintf("USE %s; EXEC sys.sp_cdc_enable_db", req.DatabaseName)
+	}
+	report.add(check)
+	return nil
+}
+
+func (c *sqlServerChecker) checkTables(ctx context.Context, db *sql.DB, req models.RegisterConnectorRequest, report *report) error {
+	defaultSchema := req.Schema
+	if defaultSchema == "" {
+		defaultSchema = "dbo"
+	}
+
+	for _, table := range req.Tables {
+		schema, name := splitTable(table, defaultSchema)
+		checkName := fmt.Sprintf("table:%s.%s", schema, name)
+
+		var tracked bool
+		err := db.QueryRowContext(ctx,
+			"SELECT t.is_tracked_by_cdc FROM sys.tables t JOIN sys.schemas s ON t.schema_id = s.schema_id WHERE s.name = @p1 AND t.name = @p2",
+			schema, name,
+		).Scan(&tracked)
+		if errors.Is(err, sql.ErrNoRows) {
+			report.add(models.PreflightCheck{
+				Name:    checkName,
+				Message: fmt.Sprintf("table %s.%s does not exist", schema, name),
+			})
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CDC state of table %s.%s: %w", schema, name, err)
+		}
+
+		check := models.PreflightCheck{
+			Name:     checkName,
+			Passed:   tracked,
+			Expected: "enabled",
+			Actual:   cdcState(tracked),
+		}
+		if !check.Passed {
+			check.Message = fmt.Sprintf("EXEC sys.sp_cdc_enable_table @source_schema = N'%s', @source_name = N'%s', @role_name = NULL", schema, name)
+		}
+		report.add(check)
+	}
+	return nil
+}
+
+func cdcState(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
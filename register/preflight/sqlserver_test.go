@@ -0,0 +1,59 @@
+package preflight
+
+import (
+	"context"
+	"database/sql"
+	"register/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func sqlServerRequest() models.RegisterConnectorRequest {
+	return models.RegisterConnectorRequest{
+		DatabaseType: models.SQLSERVER,
+		DatabaseHost: "mssql",
+		DatabasePort: 1433,
+		DatabaseName: "orders",
+		Username:     "debezium",
+		Password:     "dbz",
+		Tables:       []string{"orders"},
+	}
+}
+
+func TestSQLServerCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		databaseCDC  *bool // nil when the database does not exist
+		tableTracked bool
+		failed       []string
+	}{
+		{name: "passes", databaseCDC: boolPtr(true), tableTracked: true},
+		{name: "CDC disabled on the database", databaseCDC: boolPtr(false), tableTracked: true, failed: []string{"database_cdc"}},
+		{name: "CDC disabled on the table", databaseCDC: boolPtr(true), failed: []string{"table:dbo.orders"}},
+		{name: "unknown database", tableTracked: true, failed: []string{"database_cdc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, mock := mockOpen(t, nil)
+			database := mock.ExpectQuery(`SELECT is_cdc_enabled FROM sys.databases`).WithArgs("orders")
+			if tt.databaseCDC == nil {
+				database.WillReturnError(sql.ErrNoRows)
+			} else {
+				database.WillReturnRows(sqlmock.NewRows([]string{"is_cdc_enabled"}).AddRow(*tt.databaseCDC))
+			}
+			mock.ExpectQuery(`SELECT t.is_tracked_by_cdc FROM sys.tables`).WithArgs("dbo", "orders").
+				WillReturnRows(sqlmock.NewRows([]string{"is_tracked_by_cdc"}).AddRow(tt.tableTracked))
+
+			report, err := NewSQLServerChecker(open, testLogger()).Check(context.Background(), sqlServerRequest())
+			if err != nil {
+				t.Fatalf("check: %v", err)
+			}
+			expectFailed(t, report, tt.failed...)
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
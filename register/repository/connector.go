@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"register/models"
+	"register/pkg/db"
+)
+
+type ConnectorRepository interface {
+	Save(ctx context.Context, connector *models.Connector) error
+	FindByName(ctx context.Context, connectorName string) (*models.Connector, error)
+	FindAll(ctx context.Context) ([]models.Connector, error)
+	FindActiveByTenant(ctx context.Context, tenant string) ([]models.Connector, error)
+	FindActiveByTopicPrefix(ctx context.Context, topicPrefix string) ([]models.Connector, error)
+	UpdateStatus(ctx context.Context, connectorName string, status string) error
+	UpdateReconciledStatus(ctx context.Context, connectorName string, status string) error
+	MarkDeleting(ctx context.Context, connectorName string) error
+	MarkDeleted(ctx context.Context, connectorName string) error
+}
+
+type connectorRepository struct {
+	qb *db.QueryBuilder
+}
+
+func NewConnectorRepository(qb *db.QueryBuilder) ConnectorRepository {
+	return &connectorRepository{qb: qb}
+}
+
+// Save inserts the connector or overwrites the existing row with the same name
+func (r *connectorRepository) Save(ctx context.Context, connector *models.Connector) error {
+	existing, err := r.FindByName(ctx, connector.ConnectorName)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		if err := r.qb.WithContext(ctx).Create(connector).Error(); err != nil {
+			return fmt.Errorf("failed to insert connector %s: %w", connector.ConnectorName, err)
+		}
+		return nil
+	}
+
+	// Select every column, struct updates skip zero values like encrypt=false
+	connector.ID = existing.ID
+	connector.CreatedAt = existing.CreatedAt
+	if err := r.qb.WithContext(ctx).Model(existing).Select("*").Omit("id", "created_at").Updates(connector).Error(); err != nil {
+		return fmt.Errorf("failed to update connector %s: %w", connector.ConnectorName, err)
+	}
+	return nil
+}
+
+// FindByName returns nil without error when the connector is not registered
+func (r *connectorRepository) FindByName(ctx context.Context, connectorName string) (*models.Connector, error) {
+	var connector models.Connector
+	err := r.qb.WithContext(ctx).Where("connector_name = ?", connectorName).First(&connector).Error()
+	if db.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find connector %s: %w", connectorName, err)
+	}
+	return &connector, nil
+}
+
+func (r *connectorRepository) FindAll(ctx context.Context) ([]models.Connector, error) {
+	var connectors []models.Connector
+	if err := r.qb.WithContext(ctx).Find(&connectors).Error(); err != nil {
+		return nil, fmt.Errorf("failed to list connectors: %w", err)
+	}
+	return connectors, nil
+}
+
+func (r *connectorRepository) FindActiveByTenant(ctx context.Context, tenant string) ([]models.Connector, error) {
+	var connectors []models.Connector
+	err := r.qb.WithContext(ctx).Where("tenant = ? AND status <> ?", tenant, models.ConnectorStatusDeleted).Find(&connectors).Error()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connectors of tenant %s: %w", tenant, err)
+	}
+	return connectors, nil
+}
+
+func (r *connectorRepository) FindActiveByTopicPrefix(ctx context.Context, topicPrefix string) ([]models.Connector, error) {
+	var connectors []models.Connector
+	err := r.qb.WithContext(ctx).Where("topic_prefix = ? AND status <> ?", topicPrefix, models.ConnectorStatusDeleted).Find(&connectors).Error()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find connectors with topic prefix %s: %w", topicPrefix, err)
+	}
+	return connectors, nil
+}
+
+// UpdateStatus records the state Kafka Connect reports. It leaves connectors
+// that are being or have been deleted alone, and drifted ones until
+// reconciliation finds them in sync again.
+func (r *connectorRepository) UpdateStatus(ctx context.Context, connectorName string, status string) error {
+	return r.updateStatusUnless(ctx, connectorName, status,
+		models.ConnectorStatusDeleting, models.ConnectorStatusDeleted, models.ConnectorStatusDrifted)
+}
+
+// UpdateReconciledStatus records the status reconciliation found, which marks
+// or clears drift
+func (r *connectorRepository) UpdateReconciledStatus(ctx context.Context, connectorName string, status string) error {
+	return r.updateStatusUnless(ctx, connectorName, status,
+		models.ConnectorStatusDeleting, models.ConnectorStatusDeleted)
+}
+
+func (r *connectorRepository) updateStatusUnless(ctx context.Context, connectorName string, status string, keep ...string) error {
+	err := r.qb.WithContext(ctx).Model(&models.Connector{}).
+		Where("connector_name = ? AND status NOT IN ?", connectorName, keep).
+		Update("status", status).
+		Error()
+	if err != nil {
+		return fmt.Errorf("failed to update status for connector %s: %w", connectorName, err)
+	}
+	return nil
+}
+
+// MarkDeleting flags the connector before it is removed from Kafka Connect,
+// so the reconciler does not re-create it while the delete is in progress
+func (r *connectorRepository) MarkDeleting(ctx context.Context, connectorName string) error {
+	return r.setStatus(ctx, connectorName, models.ConnectorStatusDeleting)
+}
+
+// MarkDeleted keeps the row as a record of what was registered
+func (r *connectorRepository) MarkDeleted(ctx context.Context, connectorName string) error {
+	return r.setStatus(ctx, connectorName, models.ConnectorStatusDeleted)
+}
+
+func (r *connectorRepository) setStatus(ctx context.Context, connectorName string, status string) error {
+	err := r.qb.WithContext(ctx).Model(&models.Connector{}).
+		Where("connector_name = ?", connectorName).
+		Update("status", status).
+		Error()
+	if err != nil {
+		return fmt.Errorf("failed to mark connector %s %s: %w", connectorName, status, err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"register/models"
+	"register/pkg/db"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func newMockRepository(t *testing.T) (ConnectorRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+	return NewConnectorRepository(db.NewQueryBuilderFromDB(gormDB)), mock
+}
+
+// boolArg matches a bound boolean parameter
+type boolArg bool
+
+func (b boolArg) Match(v driver.Value) bool {
+	actual, ok := v.(bool)
+	return ok && actual == bool(b)
+}
+
+func TestSaveWritesZeroValues(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT \* FROM "connectors" WHERE connector_name = \$1`).
+		WithArgs("orders", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "connector_name", "schema", "encrypt", "trust_server_certificate", "created_at"}).
+			AddRow(7, "orders", "dbo", true, true, created))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "connectors" SET .*"schema"=\$\d+,"encrypt"=\$\d+,"trust_server_certificate"=\$\d+.* WHERE "id" = \$\d+`).
+		WithArgs(
+			"orders",         // connector_name
+			"",               // tenant
+			"sqlserver",      // database_type
+			"mssql",          // database_host
+			1433,             // database_port
+			"orders",         // database_name
+			"orders",         // topic_prefix
+			"debezium",       // username
+			sqlmock.AnyArg(), // tables
+			"",               // snapshot_mode
+			0,                // server_id
+			"",               // schema
+			boolArg(false),   // encrypt
+			boolArg(false),   // trust_server_certificate
+			"",               // capture_mode
+			"",               // pdb_name
+			"",               // log_mining_strategy
+			sqlmock.AnyArg(), // transforms
+			"RUNNING",        // status
+			sqlmock.AnyArg(), // config
+			"",               // created_by
+			sqlmock.AnyArg(), // updated_at
+			7,                // id
+		).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.Save(context.Background(), &models.Connector{
+		ConnectorName: "orders",
+		DatabaseType:  string(models.SQLSERVER),
+		DatabaseHost:  "mssql",
+		DatabasePort:  1433,
+		DatabaseName:  "orders",
+		TopicPrefix:   "orders",
+		Username:      "debezium",
+		Status:        "RUNNING",
+	})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateStatusKeepsDrift(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(ConnectorRepository) error
+		keep   []driver.Value
+	}{
+		{
+			name:   "live status",
+			update: func(r ConnectorRepository) error { return r.UpdateStatus(context.Background(), "orders", "RUNNING") },
+			keep:   []driver.Value{models.ConnectorStatusDeleting, models.ConnectorStatusDeleted, models.ConnectorStatusDrifted},
+		},
+		{
+			name: "reconciled status",
+			update: func(r ConnectorRepository) error {
+				return r.UpdateReconciledStatus(context.Background(), "orders", "RUNNING")
+			},
+			keep: []driver.Value{models.ConnectorStatusDeleting, models.ConnectorStatusDeleted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := newMockRepository(t)
+
+			args := append([]driver.Value{"RUNNING", sqlmock.AnyArg(), "orders"}, tt.keep...)
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE "connectors" SET "status"=\$1,"updated_at"=\$2 WHERE connector_name = \$3 AND status NOT IN \(.*\)`).
+				WithArgs(args...).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			if err := tt.update(repo); err != nil {
+				t.Fatalf("update: %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -1,84 +1,340 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"go.uber.org/zap"
 	"register/models"
+	"register/pkg/connect"
 	"time"
 )
 
 // Register a new connector
-func (s *cDCRegistrationService) RegisterConnector(req models.RegisterConnectorRequest) (*models.ConnectorResponse, error) {
+func (s *cDCRegistrationService) RegisterConnector(ctx context.Context, req models.RegisterConnectorRequest) (*models.ConnectorResponse, error) {
 	s.log.Info("Registering connector: %s for %s database", zap.Any("connector", req.ConnectorName), zap.Any("db", req.DatabaseType))
 
+	// Make sure the source database is ready for CDC
+	if err := s.runPreflight(ctx, req); err != nil {
+		return nil, err
+	}
+
 	// Build connector configuration based on database type
 	config, err := s.buildConnectorConfig(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build connector config: %w", err)
 	}
+	if err := checkTopicPrefixScope(ctx, config); err != nil {
+		return nil, err
+	}
+
+	// Validate configuration before creating anything
+	validation, err := s.validateConfig(ctx, req.ConnectorName, config["config"].(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	if !validation.Valid {
+		return nil, &ValidationError{Result: validation}
+	}
+
+	// Never overwrite the secrets of a connector that is already registered
+	unlock := s.registering.Lock(req.ConnectorName)
+	defer unlock()
+	existing, err := s.repo.FindByName(ctx, req.ConnectorName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Status != models.ConnectorStatusDeleted {
+		return nil, fmt.Errorf("%w: %s", ErrConnectorAlreadyRegistered, req.ConnectorName)
+	}
+	if err := s.checkTopicPrefix(ctx, req.ConnectorName, req.TopicPrefix); err != nil {
+		return nil, err
+	}
+	unlockQuota := s.lockQuota(req.Tenant)
+	defer unlockQuota()
+	if err := s.checkQuota(ctx, req.Tenant, req.ConnectorName, flattenConfig(config["config"].(map[string]interface{}))); err != nil {
+		return nil, err
+	}
+
+	// A connector missing from the registry may still run in Kafka Connect, its
+	// secrets must survive
+	if err := s.checkConnectorAbsent(ctx, req.ConnectorName); err != nil {
+		return nil, err
+	}
+
+	// Move credentials to the secret store before they reach Kafka Connect
+	if err := s.externalizeSecrets(req.ConnectorName, config["config"].(map[string]interface{})); err != nil {
+		return nil, err
+	}
 
 	// Create connector via Kafka Connect REST API
-	var createResp interface{}
-	createURL := fmt.Sprintf("%s/connectors", s.cfg.ConnectorUrl)
-	if err := s.client.Post(createURL, config, &createResp); err != nil {
+	desired := flattenConfig(config["config"].(map[string]interface{}))
+	create := connect.CreateConnectorRequest{Name: req.ConnectorName, Config: desired}
+	if _, err := s.connect.CreateConnector(ctx, create, s.policy(connectCreate)); err != nil {
+		// Keep the secrets when the connector may have been created anyway, or
+		// was created by someone else
+		if connect.IsRejected(err) && !connect.IsConflict(err) {
+			s.deleteSecrets(req.ConnectorName)
+		}
 		return nil, fmt.Errorf("failed to create connector: %w", err)
 	}
 
-	// Wait briefly and check status
-	time.Sleep(2 * time.Second)
-	status, err := s.getConnectorStatus(req.ConnectorName)
+	s.recordConnector(ctx, req, desired, models.OperationPending)
+
+	// Tasks take a while to start, poll them in the background
+	op, err := s.startRegistration(ctx, req.ConnectorName, req.Tenant)
 	if err != nil {
-		s.log.Warn("Failed to get connector status after creation: %v", zap.Any("err", err))
+		return nil, err
 	}
 
-	response := &models.ConnectorResponse{
+	return &models.ConnectorResponse{
 		ConnectorName: req.ConnectorName,
-		Status:        "created",
-		Config:        flattenConfig(config["config"].(map[string]interface{})),
-		CreatedAt:     time.Now().Format(time.RFC3339),
+		OperationID:   op.ID,
+		Status:        op.State,
+		Config:        s.redact.Map(desired),
+		CreatedAt:     op.CreatedAt,
+	}, nil
+}
+
+// Update connector configuration in place
+func (s *cDCRegistrationService) UpdateConnectorConfig(ctx context.Context, connectorName string, update models.UpdateConnectorRequest) (*models.UpdateConnectorResponse, error) {
+	record, err := s.repo.FindByName(ctx, connectorName)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.Removed() {
+		return nil, fmt.Errorf("%w: %s", ErrConnectorNotRegistered, connectorName)
 	}
 
-	if status != nil {
-		response.Status = status.Connector.State
+	before, err := s.connect.ConnectorConfig(ctx, connectorName, s.policy(connectRead))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config for connector %s: %w", connectorName, err)
 	}
 
-	return response, nil
-}
+	req := record.Request()
+	userKey, passwordKey := "database.user", "database.password"
+	if req.DatabaseType == models.MONGODB {
+		userKey, passwordKey = "mongodb.user", "mongodb.password"
+		req.ConnectionString = before["mongodb.connection.string"]
+	}
+	req.Password = before[passwordKey]
+	if req.Username == "" {
+		req.Username = before[userKey]
+	}
+	applyUpdate(&req, update)
+
+	config, err := s.buildConnectorConfig(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build connector config: %w", err)
+	}
+	if err := checkTopicPrefixScope(ctx, config); err != nil {
+		return nil, err
+	}
+	if err := s.checkTopicPrefix(ctx, connectorName, req.TopicPrefix); err != nil {
+		return nil, err
+	}
+	unlockQuota := s.lockQuota(req.Tenant)
+	defer unlockQuota()
+	if err := s.checkQuota(ctx, req.Tenant, connectorName, flattenConfig(config["config"].(map[string]interface{}))); err != nil {
+		return nil, err
+	}
+	if err := s.externalizeSecrets(connectorName, config["config"].(map[string]interface{})); err != nil {
+		return nil, err
+	}
+	after := flattenConfig(config["config"].(map[string]interface{}))
+
+	if _, err := s.connect.PutConnectorConfig(ctx, connectorName, after, s.policy(connectUpdate)); err != nil {
+		return nil, fmt.Errorf("failed to update config for connector %s: %w", connectorName, err)
+	}
+
+	s.recordConnector(ctx, req, after, record.Status)
 
-// List all connectors
-func (s *cDCRegistrationService) ListConnectors() (*models.ListConnectorsResponse, error) {
-	url := fmt.Sprintf("%s/connectors", s.cfg.ConnectorUrl)
+	changes := []models.ConfigChange{}
+	for _, d := range diffConfig(after, before) {
+		changes = append(changes, models.ConfigChange{
+			Key:    d.Key,
+			Before: s.redact.Value(d.Key, d.Actual),
+			After:  s.redact.Value(d.Key, d.Desired),
+		})
+	}
 
-	var connectors []string
-	if err := s.client.Get(url, &connectors); err != nil {
+	s.log.Info("Connector config updated", zap.String("connector", connectorName), zap.Int("changes", len(changes)))
+
+	return &models.UpdateConnectorResponse{
+		ConnectorName: connectorName,
+		Before:        s.redact.Map(before),
+		After:         s.redact.Map(after),
+		Changes:       changes,
+		UpdatedAt:     time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// List connectors, only the tenant's own when tenantName is set and only those
+// the caller's role is scoped to
+func (s *cDCRegistrationService) ListConnectors(ctx context.Context, tenantName string) (*models.ListConnectorsResponse, error) {
+	connectors, err := s.connect.ConnectorNames(ctx, s.policy(connectRead))
+	if err != nil {
 		return nil, fmt.Errorf("failed to get connectors: %w", err)
 	}
 
+	registered := make(map[string]models.Connector)
+	records, err := s.repo.FindAll(ctx)
+	if err != nil {
+		s.log.Warn("Failed to load connector registry", zap.Error(err))
+	}
+	for _, record := range records {
+		registered[record.ConnectorName] = record
+	}
+
+	visible := s.visibleConnectors(ctx, tenantName)
+	summaries := make([]models.ConnectorSummary, 0, len(connectors))
+	for _, name := range connectors {
+		if !visible(name) {
+			continue
+		}
+		summary := models.ConnectorSummary{Name: name}
+		if record, ok := registered[name]; ok {
+			summary.Registered = true
+			summary.Tenant = record.Tenant
+			summary.DatabaseType = record.DatabaseType
+			summary.DatabaseHost = record.DatabaseHost
+			summary.TopicPrefix = record.TopicPrefix
+			summary.Status = record.Status
+			summary.CreatedBy = record.CreatedBy
+			summary.CreatedAt = record.CreatedAt.Format(time.RFC3339)
+		}
+		summaries = append(summaries, summary)
+	}
+
 	return &models.ListConnectorsResponse{
-		Connectors: connectors,
+		Connectors: summaries,
 	}, nil
 }
 
 // Get connector status
-func (s *cDCRegistrationService) GetConnectorStatus(connectorName string) (*models.ConnectorStatus, error) {
-	url := fmt.Sprintf("%s/connectors/%s/status", s.cfg.ConnectorUrl, connectorName)
-
-	var status models.ConnectorStatus
-	if err := s.client.Get(url, &status); err != nil {
+func (s *cDCRegistrationService) GetConnectorStatus(ctx context.Context, connectorName string) (*models.ConnectorStatus, error) {
+	status, err := s.connect.ConnectorStatus(ctx, connectorName, s.policy(connectRead))
+	if err != nil {
 		return nil, fmt.Errorf("failed to get status for connector %s: %w", connectorName, err)
 	}
 
-	return &status, nil
+	if err := s.repo.UpdateStatus(ctx, connectorName, status.Connector.State); err != nil {
+		s.log.Warn("Failed to update connector status in registry", zap.String("connector", connectorName), zap.Error(err))
+	}
+
+	return s.redactStatus(toConnectorStatus(status)), nil
 }
 
-// Delete connector
-func (s *cDCRegistrationService) DeleteConnector(connectorName string) error {
-	url := fmt.Sprintf("%s/connectors/%s", s.cfg.ConnectorUrl, connectorName)
+// ConnectorStatuses gets the status of every connector in one Kafka Connect
+// request, nil for a connector whose status Connect did not include. The
+// registry is only written for connectors whose state changed.
+func (s *cDCRegistrationService) ConnectorStatuses(ctx context.Context) (map[string]*models.ConnectorStatus, error) {
+	expanded, err := s.connect.ExpandedConnectors(ctx, s.policy(connectRead))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connectors: %w", err)
+	}
+
+	registered := make(map[string]string)
+	records, err := s.repo.FindAll(ctx)
+	if err != nil {
+		s.log.Warn("Failed to load connector registry", zap.Error(err))
+	}
+	for _, record := range records {
+		registered[record.ConnectorName] = record.Status
+	}
+
+	statuses := make(map[string]*models.ConnectorStatus, len(expanded))
+	for name, connector := range expanded {
+		if connector.Status == nil {
+			statuses[name] = nil
+			continue
+		}
+		status := s.redactStatus(toConnectorStatus(connector.Status))
+		status.Name = name
+		statuses[name] = status
+
+		if current, ok := registered[name]; ok && current != status.Connector.State {
+			s.updateRegistryStatus(ctx, name, status.Connector.State)
+		}
+	}
+	return statuses, nil
+}
+
+// Pause connector and all of its tasks
+func (s *cDCRegistrationService) PauseConnector(ctx context.Context, connectorName string) (*models.ConnectorStatus, error) {
+	if err := s.connect.PauseConnector(ctx, connectorName, s.policy(connectControl)); err != nil {
+		return nil, fmt.Errorf("failed to pause connector %s: %w", connectorName, err)
+	}
 
-	if err := s.client.Delete(url); err != nil {
+	s.log.Info("Connector paused", zap.String("connector", connectorName))
+	return s.GetConnectorStatus(ctx, connectorName)
+}
+
+// Resume a paused connector
+func (s *cDCRegistrationService) ResumeConnector(ctx context.Context, connectorName string) (*models.ConnectorStatus, error) {
+	if err := s.connect.ResumeConnector(ctx, connectorName, s.policy(connectControl)); err != nil {
+		return nil, fmt.Errorf("failed to resume connector %s: %w", connectorName, err)
+	}
+
+	s.log.Info("Connector resumed", zap.String("connector", connectorName))
+	return s.GetConnectorStatus(ctx, connectorName)
+}
+
+// Restart connector, optionally together with its (failed) tasks
+func (s *cDCRegistrationService) RestartConnector(ctx context.Context, connectorName string, req models.RestartConnectorRequest) (*models.ConnectorStatus, error) {
+	restart := connect.RestartOptions{IncludeTasks: req.IncludeTasks, OnlyFailed: req.OnlyFailed}
+	if err := s.connect.RestartConnector(ctx, connectorName, restart, s.policy(connectControl)); err != nil {
+		return nil, fmt.Errorf("failed to restart connector %s: %w", connectorName, err)
+	}
+
+	s.log.Info("Connector restarted",
+		zap.String("connector", connectorName),
+		zap.Bool("include_tasks", req.IncludeTasks),
+		zap.Bool("only_failed", req.OnlyFailed),
+	)
+	return s.GetConnectorStatus(ctx, connectorName)
+}
+
+// Restart a single connector task
+func (s *cDCRegistrationService) RestartTask(ctx context.Context, connectorName string, taskID int) (*models.ConnectorStatus, error) {
+	if err := s.restartTask(ctx, connectorName, taskID); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Connector task restarted", zap.String("connector", connectorName), zap.Int("task", taskID))
+	return s.GetConnectorStatus(ctx, connectorName)
+}
+
+// Delete connector. The registry record is flagged before anything is removed
+// so the reconciler never re-creates a connector that is going away, a failed
+// delete leaves it DELETING and can be retried.
+func (s *cDCRegistrationService) DeleteConnector(ctx context.Context, connectorName string) error {
+	if err := s.repo.MarkDeleting(ctx, connectorName); err != nil {
+		return err
+	}
+
+	if err := s.connect.DeleteConnector(ctx, connectorName, s.policy(connectDelete)); err != nil {
 		return fmt.Errorf("failed to delete connector %s: %w", connectorName, err)
 	}
 
+	s.deleteSecrets(connectorName)
+
+	if err := s.repo.MarkDeleted(ctx, connectorName); err != nil {
+		return err
+	}
+
 	s.log.Info("Connector %s deleted successfully", zap.String("connector", connectorName))
 	return nil
 }
+
+// checkConnectorAbsent fails unless Kafka Connect does not know connectorName
+func (s *cDCRegistrationService) checkConnectorAbsent(ctx context.Context, connectorName string) error {
+	_, err := s.connect.Connector(ctx, connectorName, s.policy(connectRead))
+	switch {
+	case err == nil:
+		return fmt.Errorf("%w: %s", ErrConnectorExists, connectorName)
+	case connect.IsNotFound(err):
+		return nil
+	default:
+		return fmt.Errorf("failed to check connector %s: %w", connectorName, err)
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"register/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+type taskKey struct {
+	connector string
+	task      int
+}
+
+// healState is the auto-heal bookkeeping for failed tasks
+type healState struct {
+	mu    sync.RWMutex
+	tasks map[taskKey]*healingTask
+}
+
+type healingTask struct {
+	models.TaskHealing
+	next         time.Time
+	runningSince time.Time // zero unless the task is RUNNING
+}
+
+func newHealState() *healState {
+	return &healState{tasks: make(map[taskKey]*healingTask)}
+}
+
+// Heal restarts FAILED tasks with exponential backoff until they keep running
+// for AutoHealStableAfter or AutoHealMaxAttempts restarts did not help. It
+// returns the tasks given up on during this pass.
+func (s *cDCRegistrationService) Heal(ctx context.Context) ([]models.TaskHealing, error) {
+	statuses, err := s.ConnectorStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var exhausted []models.TaskHealing
+	seen := make(map[taskKey]bool)
+	for name, status := range statuses {
+		if status == nil {
+			s.log.Warn("Auto-heal got no status for connector", zap.String("connector", name))
+			continue
+		}
+
+		for _, task := range status.Tasks {
+			key := taskKey{connector: name, task: task.ID}
+			seen[key] = true
+			if task.State != "FAILED" {
+				s.observeHealing(key, task.State)
+				continue
+			}
+			if gaveUp := s.healTask(ctx, key, task.Trace); gaveUp != nil {
+				exhausted = append(exhausted, *gaveUp)
+			}
+		}
+	}
+
+	// Drop tasks of deleted connectors or removed tasks, keep the history of
+	// connectors whose status could not be read
+	s.healing.mu.Lock()
+	for key := range s.healing.tasks {
+		status, listed := statuses[key.connector]
+		if !listed || (status != nil && !seen[key]) {
+			delete(s.healing.tasks, key)
+		}
+	}
+	s.healing.mu.Unlock()
+
+	return exhausted, nil
+}
+
+// healTask restarts a failed task when its backoff has passed, it returns the
+// task once when the attempt budget runs out
+func (s *cDCRegistrationService) healTask(ctx context.Context, key taskKey, trace string) *models.TaskHealing {
+	s.healing.mu.Lock()
+
+	now := time.Now()
+	t, ok := s.healing.tasks[key]
+	if !ok {
+		t = &healingTask{
+			TaskHealing: models.TaskHealing{
+				ConnectorName: key.connector,
+				TaskID:        key.task,
+				FailedAt:      now.Format(time.RFC3339),
+				Attempts:      []models.HealAttempt{},
+			},
+			next: now,
+		}
+		s.healing.tasks[key] = t
+	}
+	t.Trace = trace
+	t.runningSince = time.Time{}
+
+	if t.GaveUp || now.Before(t.next) {
+		s.healing.mu.Unlock()
+		return nil
+	}
+
+	if len(t.Attempts) >= s.cfg.AutoHealMaxAttempts {
+		t.GaveUp = true
+		t.NextAttemptAt = ""
+		gaveUp := t.TaskHealing
+		gaveUp.Attempts = append([]models.HealAttempt(nil), t.Attempts...)
+		s.healing.mu.Unlock()
+
+		s.log.Error("Auto-heal gave up on failed task",
+			zap.String("connector", key.connector),
+			zap.Int("task", key.task),
+			zap.Int("attempts", len(gaveUp.Attempts)),
+		)
+		return &gaveUp
+	}
+
+	// Hold the next attempt back before restarting without the lock
+	attempt := models.HealAttempt{
+		Attempt:     len(t.Attempts) + 1,
+		Trace:       trace,
+		RestartedAt: now.Format(time.RFC3339),
+	}
+	t.next = now.Add(s.healBackoff(attempt.Attempt))
+	t.NextAttemptAt = t.next.Format(time.RFC3339)
+	s.healing.mu.Unlock()
+
+	if err := s.restartTask(ctx, key.connector, key.task); err != nil {
+		attempt.Error = s.redact.Text(err.Error())
+	}
+
+	s.healing.mu.Lock()
+	t.Attempts = append(t.Attempts, attempt)
+	s.healing.mu.Unlock()
+
+	s.log.Info("Auto-heal restarted failed task",
+		zap.String("connector", key.connector),
+		zap.Int("task", key.task),
+		zap.Int("attempt", attempt.Attempt),
+	)
+	return nil
+}
+
+// healBackoff doubles the initial backoff after every attempt, up to the maximum
+func (s *cDCRegistrationService) healBackoff(attempts int) time.Duration {
+	backoff := s.cfg.AutoHealBackoff
+	for i := 1; i < attempts && backoff < s.cfg.AutoHealMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > s.cfg.AutoHealMaxBackoff {
+		backoff = s.cfg.AutoHealMaxBackoff
+	}
+	return backoff
+}
+
+// observeHealing forgets a tracked task once it has kept RUNNING for
+// AutoHealStableAfter. Restarted tasks pass through RESTARTING and RUNNING
+// before they fail again, so any other state keeps the attempt history.
+func (s *cDCRegistrationService) observeHealing(key taskKey, state string) {
+	s.healing.mu.Lock()
+	defer s.healing.mu.Unlock()
+
+	t, ok := s.healing.tasks[key]
+	if !ok {
+		return
+	}
+	if state != "RUNNING" {
+		t.runningSince = time.Time{}
+		return
+	}
+	now := time.Now()
+	if t.runningSince.IsZero() {
+		t.runningSince = now
+	}
+	if now.Sub(t.runningSince) < s.cfg.AutoHealStableAfter {
+		return
+	}
+
+	delete(s.healing.tasks, key)
+	if len(t.Attempts) > 0 {
+		s.log.Info("Auto-healed task recovered",
+			zap.String("connector", key.connector),
+			zap.Int("task", key.task),
+			zap.Int("attempts", len(t.Attempts)),
+		)
+	}
+}
+
+// Get the failed tasks auto-heal is tracking, tenants only see their own
+func (s *cDCRegistrationService) GetHealingReport(ctx context.Context, tenantName string) (*models.HealingReport, error) {
+	visible := s.visibleConnectors(ctx, tenantName)
+
+	s.healing.mu.RLock()
+	defer s.healing.mu.RUnlock()
+
+	report := &models.HealingReport{Tasks: []models.TaskHealing{}}
+	for key, t := range s.healing.tasks {
+		if !visible(key.connector) {
+			continue
+		}
+		healing := t.TaskHealing
+		healing.Attempts = append([]models.HealAttempt(nil), t.Attempts...)
+		report.Tasks = append(report.Tasks, healing)
+	}
+
+	sort.Slice(report.Tasks, func(i, j int) bool {
+		if report.Tasks[i].ConnectorName != report.Tasks[j].ConnectorName {
+			return report.Tasks[i].ConnectorName < report.Tasks[j].ConnectorName
+		}
+		return report.Tasks[i].TaskID < report.Tasks[j].TaskID
+	})
+	return report, nil
+}
+
+func (s *cDCRegistrationService) restartTask(ctx context.Context, connectorName string, taskID int) error {
+	if err := s.connect.RestartTask(ctx, connectorName, taskID, s.policy(connectControl)); err != nil {
+		return fmt.Errorf("failed to restart task %d of connector %s: %w", taskID, connectorName, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"register/pkg/logger"
+	"register/pkg/webhook"
+	"time"
+)
+
+type Healer interface {
+	Start(ctx context.Context)
+}
+
+type healer struct {
+	service  CDCRegistrationService
+	webhooks webhook.Dispatcher // nil only logs give-ups
+	interval time.Duration
+	log      logger.Logger
+}
+
+func NewHealer(service CDCRegistrationService, webhooks webhook.Dispatcher, interval time.Duration, log logger.Logger) Healer {
+	return &healer{
+		service:  service,
+		webhooks: webhooks,
+		interval: interval,
+		log:      log,
+	}
+}
+
+// Start restarts failed tasks and alerts about the ones auto-heal gives up on
+func (h *healer) Start(ctx context.Context) {
+	h.log.Info("Starting auto-heal", zap.String("interval", h.interval.String()))
+
+	runEvery(ctx, h.interval, h.heal)
+	h.log.Info("Stopping auto-heal")
+}
+
+func (h *healer) heal(ctx context.Context) {
+	exhausted, err := h.service.Heal(ctx)
+	if err != nil {
+		h.log.Error("Auto-heal failed", zap.Error(err))
+	}
+	for _, task := range exhausted {
+		h.alert(task.ConnectorName, task.TaskID, len(task.Attempts), task.Trace)
+	}
+}
+
+func (h *healer) alert(connectorName string, taskID int, attempts int, trace string) {
+	if h.webhooks == nil {
+		return
+	}
+	id, err := newID()
+	if err != nil {
+		h.log.Error("Failed to create webhook event", zap.Error(err))
+		return
+	}
+	h.webhooks.Dispatch(webhook.Event{
+		ID:        id,
+		Type:      webhook.TaskHealExhausted,
+		Connector: connectorName,
+		TaskID:    &taskID,
+		From:      "FAILED",
+		To:        "FAILED",
+		Trace:     trace,
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+	h.log.Info("Sent auto-heal alert",
+		zap.String("connector", connectorName),
+		zap.Int("task", taskID),
+		zap.Int("attempts", attempts),
+	)
+}
@@ -0,0 +1,41 @@
+package service
+
+import "sync"
+
+// keyedMutex serializes work on the same key, e.g. registrations of one connector
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	waiters int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*keyedLock)}
+}
+
+// Lock locks key and returns the function that unlocks it
+func (m *keyedMutex) Lock(key string) func() {
+	m.mu.Lock()
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		m.locks[key] = l
+	}
+	l.waiters++
+	m.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		m.mu.Lock()
+		l.waiters--
+		if l.waiters == 0 {
+			delete(m.locks, key)
+		}
+		m.mu.Unlock()
+	}
+}
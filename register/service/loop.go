@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// runEvery is the loop of the background workers: it calls fn right away and
+// then on every tick of interval, and returns once ctx is done. A run that
+// outlasts interval delays the next one instead of overlapping it.
+func runEvery(ctx context.Context, interval time.Duration, fn func(context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fn(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
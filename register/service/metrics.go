@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"register/pkg/logger"
+	"register/pkg/metrics"
+	"time"
+)
+
+type MetricsRefresher interface {
+	Start(ctx context.Context)
+}
+
+type metricsRefresher struct {
+	service  CDCRegistrationService
+	interval time.Duration
+	log      logger.Logger
+}
+
+// NewMetricsRefresher keeps the connector and task state gauges up to date
+// from the Kafka Connect status API
+func NewMetricsRefresher(service CDCRegistrationService, interval time.Duration, log logger.Logger) MetricsRefresher {
+	return &metricsRefresher{
+		service:  service,
+		interval: interval,
+		log:      log,
+	}
+}
+
+// Start updates the connector and task state gauges
+func (m *metricsRefresher) Start(ctx context.Context) {
+	m.log.Info("Starting connector metrics refresher", zap.String("interval", m.interval.String()))
+
+	runEvery(ctx, m.interval, m.refresh)
+	m.log.Info("Stopping connector metrics refresher")
+}
+
+func (m *metricsRefresher) refresh(ctx context.Context) {
+	statuses, err := m.service.ConnectorStatuses(ctx)
+	if err != nil {
+		m.log.Warn("Failed to get connector statuses for metrics", zap.Error(err))
+		return
+	}
+
+	connectorStates := make(map[string]int)
+	taskStates := make(map[string]int)
+	for _, status := range statuses {
+		if status == nil {
+			connectorStates["UNKNOWN"]++
+			continue
+		}
+		connectorStates[status.Connector.State]++
+		for _, task := range status.Tasks {
+			taskStates[task.State]++
+		}
+	}
+
+	metrics.SetConnectorStates(connectorStates, taskStates)
+}
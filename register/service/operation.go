@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"register/models"
+	"register/pkg/tracing"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrOperationNotFound = errors.New("operation not found")
+
+const operationRegister = "register"
+
+// operationStore keeps operations in memory. They only matter while a
+// registration settles, the registry holds the durable connector state.
+type operationStore struct {
+	mu         sync.RWMutex
+	operations map[string]*models.Operation
+	finished   map[string]time.Time
+}
+
+func newOperationStore() *operationStore {
+	return &operationStore{
+		operations: make(map[string]*models.Operation),
+		finished:   make(map[string]time.Time),
+	}
+}
+
+// add stores op and drops finished operations older than retention
+func (o *operationStore) add(op *models.Operation, retention time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for id, finishedAt := range o.finished {
+		if time.Since(finishedAt) > retention {
+			delete(o.operations, id)
+			delete(o.finished, id)
+		}
+	}
+	o.operations[op.ID] = op
+}
+
+// get returns a copy so callers never race with the poller
+func (o *operationStore) get(id string) (models.Operation, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	op, ok := o.operations[id]
+	if !ok {
+		return models.Operation{}, false
+	}
+	return *op, true
+}
+
+// update applies fn to the operation and reports whether it is done
+func (o *operationStore) update(id string, fn func(op *models.Operation)) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	op, ok := o.operations[id]
+	if !ok {
+		return true
+	}
+	fn(op)
+	op.UpdatedAt = time.Now().Format(time.RFC3339)
+	if op.Done() {
+		op.FinishedAt = op.UpdatedAt
+		o.finished[id] = time.Now()
+	}
+	return op.Done()
+}
+
+// Get a registration operation, tenants only see their own
+func (s *cDCRegistrationService) GetOperation(ctx context.Context, id string, tenantName string) (*models.Operation, error) {
+	op, ok := s.operations.get(id)
+	if !ok || (tenantName != "" && op.Tenant != tenantName) || !s.visibleConnectors(ctx, tenantName)(op.ConnectorName) {
+		return nil, fmt.Errorf("%w: %s", ErrOperationNotFound, id)
+	}
+	return &op, nil
+}
+
+// startRegistration tracks a connector Kafka Connect just accepted and polls it
+// in the background until it settles or the registration timeout passes
+func (s *cDCRegistrationService) startRegistration(ctx context.Context, connectorName, tenantName string) (*models.Operation, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	op := &models.Operation{
+		ID:            id,
+		Type:          operationRegister,
+		ConnectorName: connectorName,
+		Tenant:        tenantName,
+		State:         models.OperationPending,
+		Progress:      "waiting for Kafka Connect to start the connector",
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	s.operations.add(op, s.cfg.OperationRetention)
+
+	// The poll outlives the request, keep its trace but not its cancellation
+	pollCtx, span := tracing.Start(context.WithoutCancel(ctx), "connector registration", trace.WithAttributes(
+		attribute.String("connector.name", connectorName),
+		attribute.String("operation.id", id),
+	))
+	go s.pollRegistration(pollCtx, span, id, connectorName)
+
+	created := *op
+	return &created, nil
+}
+
+func (s *cDCRegistrationService) pollRegistration(ctx context.Context, span trace.Span, id, connectorName string) {
+	deadline := time.Now().Add(s.cfg.RegistrationTimeout)
+	ticker := time.NewTicker(s.cfg.RegistrationPollInterval)
+	defer ticker.Stop()
+	defer func() {
+		op, _ := s.operations.get(id)
+		span.SetAttributes(attribute.String("operation.state", op.State))
+		span.End()
+	}()
+
+	for range ticker.C {
+		// Kafka Connect answers 404 until a worker picks the connector up, keep polling
+		status, err := s.getConnectorStatus(ctx, connectorName)
+		if err == nil {
+			s.updateRegistryStatus(ctx, connectorName, status.Connector.State)
+			status = s.redactStatus(status)
+		}
+
+		done := s.operations.update(id, func(op *models.Operation) {
+			if err != nil {
+				op.Error = s.redact.Text(err.Error())
+				return
+			}
+			op.Error = ""
+			applyStatus(op, status)
+		})
+		if done {
+			op, _ := s.operations.get(id)
+			s.log.Info("Connector registration finished",
+				zap.String("connector", connectorName),
+				zap.String("operation", id),
+				zap.String("state", op.State),
+			)
+			return
+		}
+
+		if time.Now().After(deadline) {
+			s.operations.update(id, func(op *models.Operation) {
+				op.State = models.OperationTimedOut
+				op.Error = fmt.Sprintf("connector did not settle within %s", s.cfg.RegistrationTimeout)
+			})
+			s.log.Warn("Connector registration timed out",
+				zap.String("connector", connectorName),
+				zap.String("operation", id),
+			)
+			return
+		}
+	}
+}
+
+// applyStatus moves the operation forward once the connector and every task
+// are RUNNING or FAILED
+func applyStatus(op *models.Operation, status *models.ConnectorStatus) {
+	op.Status = status
+	op.TasksTotal = len(status.Tasks)
+	op.TasksRunning, op.TasksFailed = 0, 0
+	for _, task := range status.Tasks {
+		switch task.State {
+		case "RUNNING":
+			op.TasksRunning++
+		case "FAILED":
+			op.TasksFailed++
+		}
+	}
+	op.Progress = fmt.Sprintf("connector %s, %d/%d tasks running", status.Connector.State, op.TasksRunning, op.TasksTotal)
+
+	switch {
+	case status.Connector.State == "FAILED":
+		op.State = models.OperationFailed
+		op.Error = "connector failed"
+	case status.Connector.State != "RUNNING" || op.TasksTotal == 0:
+		// Tasks are not created until the connector is running
+	case op.TasksRunning+op.TasksFailed < op.TasksTotal:
+		// Some tasks are still starting
+	case op.TasksFailed > 0:
+		op.State = models.OperationFailed
+		op.Error = fmt.Sprintf("%d of %d tasks failed", op.TasksFailed, op.TasksTotal)
+	default:
+		op.State = models.OperationSucceeded
+	}
+}
+
+// newID returns a random hex id for operations and events
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"register/models"
+	"register/preflight"
+)
+
+// PreflightError is returned when the source database is not ready for CDC
+type PreflightError struct {
+	Report *models.PreflightReport
+}
+
+func (e *PreflightError) Error() string {
+	var failed int
+	for _, check := range e.Report.Checks {
+		if !check.Passed {
+			failed++
+		}
+	}
+	return fmt.Sprintf("source database failed %d pre-flight check(s)", failed)
+}
+
+// Run the pre-flight checks against the source database
+func (s *cDCRegistrationService) Preflight(ctx context.Context, req models.RegisterConnectorRequest) (*models.PreflightReport, error) {
+	report, err := s.preflight.Check(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.redactReport(report), nil
+}
+
+// runPreflight is the registration gate, database types without checks are let
+// through. Skipping it is only allowed when the server is configured to.
+func (s *cDCRegistrationService) runPreflight(ctx context.Context, req models.RegisterConnectorRequest) error {
+	if req.SkipPreflight {
+		if !s.cfg.AllowSkipPreflight {
+			return ErrSkipPreflightNotAllowed
+		}
+		s.log.Warn("Skipping pre-flight checks", zap.String("connector", req.ConnectorName))
+		return nil
+	}
+
+	report, err := s.preflight.Check(ctx, req)
+	if errors.Is(err, preflight.ErrUnsupportedDatabase) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run pre-flight checks: %w", err)
+	}
+	if !report.Passed {
+		return &PreflightError{Report: s.redactReport(report)}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"register/models"
+	"register/pkg/connect"
+	"register/pkg/redact"
+	"sort"
+	"time"
+)
+
+var ErrNoReconciliationReport = errors.New("no reconciliation has completed yet")
+
+// Reconcile diffs the desired connectors in the registry against Kafka Connect.
+// Missing connectors are re-created, edited ones are flagged as drifted and
+// connectors the registry does not know about are reported as orphans.
+func (s *cDCRegistrationService) Reconcile(ctx context.Context) (*models.ReconciliationReport, error) {
+	report := &models.ReconciliationReport{
+		StartedAt: time.Now().Format(time.RFC3339),
+		InSync:    []string{},
+		Recreated: []string{},
+		Drifted:   []models.ConnectorDrift{},
+		Orphans:   []string{},
+		Failures:  []models.ReconciliationFailure{},
+	}
+
+	records, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load desired connectors: %w", err)
+	}
+
+	actual, err := s.connect.ExpandedConnectors(ctx, s.policy(connectRead))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connectors: %w", err)
+	}
+
+	known := make(map[string]bool)
+	for _, record := range records {
+		known[record.ConnectorName] = true
+		if record.Removed() {
+			continue
+		}
+
+		current, ok := actual[record.ConnectorName]
+		if !ok {
+			s.recreateConnector(ctx, record, report)
+			continue
+		}
+
+		var actualConfig map[string]string
+		if current.Info != nil {
+			actualConfig = current.Info.Config
+		}
+		if differences := s.redactDifferences(diffConfig(record.Config, actualConfig)); len(differences) > 0 {
+			report.Drifted = append(report.Drifted, models.ConnectorDrift{
+				ConnectorName: record.ConnectorName,
+				Differences:   differences,
+			})
+			s.updateReconciledStatus(ctx, record.ConnectorName, models.ConnectorStatusDrifted)
+			continue
+		}
+
+		report.InSync = append(report.InSync, record.ConnectorName)
+		if current.Status != nil {
+			s.updateReconciledStatus(ctx, record.ConnectorName, current.Status.Connector.State)
+		}
+	}
+
+	for name := range actual {
+		if !known[name] {
+			report.Orphans = append(report.Orphans, name)
+		}
+	}
+	sort.Strings(report.Orphans)
+
+	report.FinishedAt = time.Now().Format(time.RFC3339)
+
+	s.reportMu.Lock()
+	s.lastReport = report
+	s.reportMu.Unlock()
+
+	s.log.Info("Reconciliation finished",
+		zap.Int("in_sync", len(report.InSync)),
+		zap.Int("recreated", len(report.Recreated)),
+		zap.Int("drifted", len(report.Drifted)),
+		zap.Int("orphans", len(report.Orphans)),
+		zap.Int("failures", len(report.Failures)),
+	)
+
+	return report, nil
+}
+
+// Get the report of the last completed reconciliation
+func (s *cDCRegistrationService) GetReconciliationReport(ctx context.Context, tenantName string) (*models.ReconciliationReport, error) {
+	visible := s.visibleConnectors(ctx, tenantName)
+
+	s.reportMu.RLock()
+	defer s.reportMu.RUnlock()
+
+	if s.lastReport == nil {
+		return nil, ErrNoReconciliationReport
+	}
+
+	// Tenants and scoped roles only see their own connectors
+	report := &models.ReconciliationReport{
+		StartedAt:  s.lastReport.StartedAt,
+		FinishedAt: s.lastReport.FinishedAt,
+		InSync:     filterNames(s.lastReport.InSync, visible),
+		Recreated:  filterNames(s.lastReport.Recreated, visible),
+		Orphans:    filterNames(s.lastReport.Orphans, visible),
+		Drifted:    []models.ConnectorDrift{},
+		Failures:   []models.ReconciliationFailure{},
+	}
+	for _, d := range s.lastReport.Drifted {
+		if visible(d.ConnectorName) {
+			report.Drifted = append(report.Drifted, d)
+		}
+	}
+	for _, f := range s.lastReport.Failures {
+		if visible(f.ConnectorName) {
+			report.Failures = append(report.Failures, f)
+		}
+	}
+	return report, nil
+}
+
+func filterNames(names []string, keep func(string) bool) []string {
+	filtered := []string{}
+	for _, name := range names {
+		if keep(name) {
+			filtered = append(filtered, name)
+		}
+	}
+	return filtered
+}
+
+func (s *cDCRegistrationService) recreateConnector(ctx context.Context, record models.Connector, report *models.ReconciliationReport) {
+	if len(record.Config) == 0 {
+		report.Failures = append(report.Failures, models.ReconciliationFailure{
+			ConnectorName: record.ConnectorName,
+			Error:         "connector is missing from Kafka Connect and has no stored config to re-create it from",
+		})
+		return
+	}
+
+	for _, value := range record.Config {
+		if value == redact.Mask {
+			report.Failures = append(report.Failures, models.ReconciliationFailure{
+				ConnectorName: record.ConnectorName,
+				Error:         "connector is missing from Kafka Connect and its credentials are not stored, register it again",
+			})
+			return
+		}
+	}
+
+	create := connect.CreateConnectorRequest{Name: record.ConnectorName, Config: record.Config}
+	if _, err := s.connect.CreateConnector(ctx, create, s.policy(connectCreate)); err != nil {
+		report.Failures = append(report.Failures, models.ReconciliationFailure{
+			ConnectorName: record.ConnectorName,
+			Error:         fmt.Sprintf("failed to re-create connector: %v", err),
+		})
+		return
+	}
+
+	s.log.Info("Re-created missing connector", zap.String("connector", record.ConnectorName))
+	report.Recreated = append(report.Recreated, record.ConnectorName)
+}
+
+func (s *cDCRegistrationService) updateRegistryStatus(ctx context.Context, connectorName string, status string) {
+	if err := s.repo.UpdateStatus(ctx, connectorName, status); err != nil {
+		s.log.Warn("Failed to update connector status in registry", zap.String("connector", connectorName), zap.Error(err))
+	}
+}
+
+// updateReconciledStatus is updateRegistryStatus for reconciliation, the only
+// writer that may mark or clear drift
+func (s *cDCRegistrationService) updateReconciledStatus(ctx context.Context, connectorName string, status string) {
+	if err := s.repo.UpdateReconciledStatus(ctx, connectorName, status); err != nil {
+		s.log.Warn("Failed to update connector status in registry", zap.String("connector", connectorName), zap.Error(err))
+	}
+}
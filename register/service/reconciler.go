@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"register/pkg/logger"
+	"time"
+)
+
+type Reconciler interface {
+	Start(ctx context.Context)
+}
+
+type reconciler struct {
+	service  CDCRegistrationService
+	interval time.Duration
+	log      logger.Logger
+}
+
+func NewReconciler(service CDCRegistrationService, interval time.Duration, log logger.Logger) Reconciler {
+	return &reconciler{
+		service:  service,
+		interval: interval,
+		log:      log,
+	}
+}
+
+// Start diffs the registry against Kafka Connect and re-creates missing connectors
+func (r *reconciler) Start(ctx context.Context) {
+	r.log.Info("Starting reconciler", zap.String("interval", r.interval.String()))
+
+	runEvery(ctx, r.interval, r.reconcile)
+	r.log.Info("Stopping reconciler")
+}
+
+func (r *reconciler) reconcile(ctx context.Context) {
+	if _, err := r.service.Reconcile(ctx); err != nil {
+		r.log.Error("Reconciliation failed", zap.Error(err))
+	}
+}
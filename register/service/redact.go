@@ -0,0 +1,30 @@
+package service
+
+import (
+	"register/models"
+)
+
+// redactStatus masks credentials that connector and task traces may contain
+func (s *cDCRegistrationService) redactStatus(status *models.ConnectorStatus) *models.ConnectorStatus {
+	status.Connector.Trace = s.redact.Text(status.Connector.Trace)
+	for i := range status.Tasks {
+		status.Tasks[i].Trace = s.redact.Text(status.Tasks[i].Trace)
+	}
+	return status
+}
+
+func (s *cDCRegistrationService) redactDifferences(differences []models.ConfigDifference) []models.ConfigDifference {
+	for i, d := range differences {
+		differences[i].Desired = s.redact.Value(d.Key, d.Desired)
+		differences[i].Actual = s.redact.Value(d.Key, d.Actual)
+	}
+	return differences
+}
+
+// redactReport masks credentials in driver errors quoted by pre-flight messages
+func (s *cDCRegistrationService) redactReport(report *models.PreflightReport) *models.PreflightReport {
+	for i := range report.Checks {
+		report.Checks[i].Message = s.redact.Text(report.Checks[i].Message)
+	}
+	return report
+}
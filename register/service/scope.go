@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"register/pkg/rbac"
+	"register/pkg/tenant"
+)
+
+// checkTopicPrefixScope checks the topic prefix Kafka Connect will get, not the
+// requested one, against the caller's role
+func checkTopicPrefixScope(ctx context.Context, config map[string]interface{}) error {
+	topicPrefix, _ := config["config"].(map[string]interface{})["topic.prefix"].(string)
+	if !rbac.FromContext(ctx).AllowsTopicPrefix(topicPrefix) {
+		return fmt.Errorf("%w %s", ErrTopicPrefixNotAllowed, topicPrefix)
+	}
+	return nil
+}
+
+// visibleConnectors returns whether the caller may see a connector: it must
+// belong to tenantName, when set, and have a topic prefix the caller's role is
+// scoped to. Scoped callers never see connectors missing from the registry.
+func (s *cDCRegistrationService) visibleConnectors(ctx context.Context, tenantName string) func(connectorName string) bool {
+	owns := func(connectorName string) bool {
+		return tenantName == "" || tenant.Owns(tenantName, connectorName)
+	}
+
+	grant := rbac.FromContext(ctx)
+	if grant == nil || len(grant.TopicPrefixes) == 0 {
+		return owns
+	}
+
+	records, err := s.repo.FindAll(ctx)
+	if err != nil {
+		s.log.Warn("Failed to load connector registry", zap.Error(err))
+	}
+	topicPrefixes := make(map[string]string, len(records))
+	for _, record := range records {
+		topicPrefixes[record.ConnectorName] = record.TopicPrefix
+	}
+	return func(connectorName string) bool {
+		topicPrefix, ok := topicPrefixes[connectorName]
+		return owns(connectorName) && ok && grant.AllowsTopicPrefix(topicPrefix)
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"register/pkg/secrets"
+)
+
+// Connector config keys whose values are moved to the secret store
+var secretConfigKeys = []string{
+	"database.password",
+	"mongodb.password",
+	"mongodb.connection.string", // may carry credentials
+}
+
+// externalizeSecrets writes the secret values of a connector config to the secret
+// store and replaces them with config provider placeholders. Values that already
+// are placeholders keep pointing at the stored secret.
+func (s *cDCRegistrationService) externalizeSecrets(connectorName string, config map[string]interface{}) error {
+	if s.secrets == nil {
+		return nil
+	}
+
+	values := make(map[string]string)
+	for _, key := range secretConfigKeys {
+		value, ok := config[key].(string)
+		if !ok || value == "" || secrets.IsPlaceholder(value) {
+			continue
+		}
+		values[key] = value
+		config[key] = s.secrets.Placeholder(connectorName, key)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	if err := s.secrets.Put(connectorName, values); err != nil {
+		return fmt.Errorf("failed to store connector secrets: %w", err)
+	}
+	return nil
+}
+
+func (s *cDCRegistrationService) deleteSecrets(connectorName string) {
+	if s.secrets == nil {
+		return
+	}
+	if err := s.secrets.Delete(connectorName); err != nil {
+		s.log.Error("Failed to delete connector secrets", zap.String("connector", connectorName), zap.Error(err))
+	}
+}
@@ -1,29 +1,109 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"register/config"
 	"register/models"
+	"register/pkg/connect"
 	"register/pkg/http"
 	"register/pkg/logger"
+	"register/pkg/redact"
+	"register/pkg/secrets"
+	"register/pkg/tenant"
+	"register/preflight"
+	"register/repository"
+	"sync"
+)
+
+var (
+	ErrConnectorNotRegistered     = errors.New("connector is not in the registry")
+	ErrConnectorAlreadyRegistered = errors.New("connector is already registered")
+	ErrConnectorExists            = errors.New("connector already exists in Kafka Connect")
+	ErrTopicPrefixInUse           = errors.New("topic prefix is already in use")
+	ErrQuotaExceeded              = errors.New("tenant quota exceeded")
+	ErrUnknownTenant              = errors.New("unknown tenant")
+	ErrTopicPrefixNotAllowed      = errors.New("role is not allowed to use topic prefix")
+	ErrSkipPreflightNotAllowed    = errors.New("skipping pre-flight checks is disabled, set ALLOW_SKIP_PREFLIGHT to allow it")
+)
+
+// Kafka Connect operations, each with its own timeout and retries
+const (
+	connectRead     = config.ConnectRead
+	connectCreate   = config.ConnectCreate
+	connectUpdate   = config.ConnectUpdate
+	connectValidate = config.ConnectValidate
+	connectControl  = config.ConnectControl
+	connectDelete   = config.ConnectDelete
 )
 
 type CDCRegistrationService interface {
-	RegisterConnector(req models.RegisterConnectorRequest) (*models.ConnectorResponse, error)
-	ListConnectors() (*models.ListConnectorsResponse, error)
-	GetConnectorStatus(connectorName string) (*models.ConnectorStatus, error)
-	DeleteConnector(connectorName string) error
+	RegisterConnector(ctx context.Context, req models.RegisterConnectorRequest) (*models.ConnectorResponse, error)
+	ValidateConnector(ctx context.Context, req models.RegisterConnectorRequest) (*models.ValidationResult, error)
+	Preflight(ctx context.Context, req models.RegisterConnectorRequest) (*models.PreflightReport, error)
+	UpdateConnectorConfig(ctx context.Context, connectorName string, update models.UpdateConnectorRequest) (*models.UpdateConnectorResponse, error)
+	ListConnectors(ctx context.Context, tenantName string) (*models.ListConnectorsResponse, error)
+	GetConnectorStatus(ctx context.Context, connectorName string) (*models.ConnectorStatus, error)
+	ConnectorStatuses(ctx context.Context) (map[string]*models.ConnectorStatus, error)
+	PauseConnector(ctx context.Context, connectorName string) (*models.ConnectorStatus, error)
+	ResumeConnector(ctx context.Context, connectorName string) (*models.ConnectorStatus, error)
+	RestartConnector(ctx context.Context, connectorName string, req models.RestartConnectorRequest) (*models.ConnectorStatus, error)
+	RestartTask(ctx context.Context, connectorName string, taskID int) (*models.ConnectorStatus, error)
+	DeleteConnector(ctx context.Context, connectorName string) error
+	Reconcile(ctx context.Context) (*models.ReconciliationReport, error)
+	GetReconciliationReport(ctx context.Context, tenantName string) (*models.ReconciliationReport, error)
+	GetTenantUsage(ctx context.Context, tenantName string) (*models.TenantUsage, error)
+	GetOperation(ctx context.Context, id string, tenantName string) (*models.Operation, error)
+	Heal(ctx context.Context) ([]models.TaskHealing, error)
+	GetHealingReport(ctx context.Context, tenantName string) (*models.HealingReport, error)
 }
 
 type cDCRegistrationService struct {
-	cfg    *config.Config
-	log    logger.Logger
-	client http.HTTPClient
+	cfg       *config.Config
+	log       logger.Logger
+	connect   connect.Client
+	repo      repository.ConnectorRepository
+	preflight preflight.Checker
+	secrets   secrets.Store // nil keeps credentials in the connector config
+	redact    *redact.Policy
+	tenants   *tenant.Directory // nil disables quotas
+
+	reportMu   sync.RWMutex
+	lastReport *models.ReconciliationReport
+
+	operations  *operationStore
+	healing     *healState
+	registering *keyedMutex // per connector name
+	quotas      *keyedMutex // per tenant with limits
 }
 
-func NewCDCRegistrationService(cfg *config.Config, log logger.Logger, c http.HTTPClient) CDCRegistrationService {
+func NewCDCRegistrationService(cfg *config.Config, log logger.Logger, c connect.Client, repo repository.ConnectorRepository, checker preflight.Checker, store secrets.Store, policy *redact.Policy, tenants *tenant.Directory) CDCRegistrationService {
 	return &cDCRegistrationService{
-		cfg:    cfg,
-		log:    log,
-		client: c,
+		cfg:       cfg,
+		log:       log,
+		connect:   c,
+		repo:      repo,
+		preflight: checker,
+		secrets:   store,
+		redact:    policy,
+		tenants:   tenants,
+
+		operations:  newOperationStore(),
+		healing:     newHealState(),
+		registering: newKeyedMutex(),
+		quotas:      newKeyedMutex(),
+	}
+}
+
+// policy returns the timeout and retries configured for a Kafka Connect operation
+func (s *cDCRegistrationService) policy(operation string) http.Option {
+	p, ok := s.cfg.ConnectPolicies[operation]
+	if !ok {
+		return http.WithPolicy(http.DefaultPolicy)
 	}
+	return http.WithPolicy(http.Policy{
+		Timeout:   p.Timeout,
+		Retries:   p.Retries,
+		RetryWait: p.RetryWait,
+	})
 }
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"register/models"
+	"strconv"
+)
+
+// checkTopicPrefix makes sure no other active connector writes to the same topics
+func (s *cDCRegistrationService) checkTopicPrefix(ctx context.Context, connectorName, topicPrefix string) error {
+	connectors, err := s.repo.FindActiveByTopicPrefix(ctx, topicPrefix)
+	if err != nil {
+		return err
+	}
+	for _, c := range connectors {
+		if c.ConnectorName != connectorName {
+			return fmt.Errorf("%w: %s is used by connector %s", ErrTopicPrefixInUse, topicPrefix, c.ConnectorName)
+		}
+	}
+	return nil
+}
+
+// checkQuota enforces the tenant's connector and task limits for a connector
+// that would run with the given config
+func (s *cDCRegistrationService) checkQuota(ctx context.Context, tenantName, connectorName string, config map[string]string) error {
+	t := s.tenants.Lookup(tenantName)
+	if t == nil || (t.MaxConnectors == 0 && t.MaxTasks == 0) {
+		return nil
+	}
+
+	connectors, err := s.repo.FindActiveByTenant(ctx, tenantName)
+	if err != nil {
+		return err
+	}
+
+	count, tasks := 1, taskCount(config)
+	for _, c := range connectors {
+		if c.ConnectorName == connectorName {
+			continue
+		}
+		count++
+		tasks += taskCount(c.Config)
+	}
+
+	if t.MaxConnectors > 0 && count > t.MaxConnectors {
+		return fmt.Errorf("%w: tenant %s is limited to %d connectors", ErrQuotaExceeded, tenantName, t.MaxConnectors)
+	}
+	if t.MaxTasks > 0 && tasks > t.MaxTasks {
+		return fmt.Errorf("%w: tenant %s is limited to %d tasks, %d requested", ErrQuotaExceeded, tenantName, t.MaxTasks, tasks)
+	}
+	return nil
+}
+
+// lockQuota serializes the connector changes of a tenant with limits, from
+// checkQuota until the connector is recorded, so concurrent requests cannot
+// all pass the check against the same usage
+func (s *cDCRegistrationService) lockQuota(tenantName string) func() {
+	t := s.tenants.Lookup(tenantName)
+	if t == nil || (t.MaxConnectors == 0 && t.MaxTasks == 0) {
+		return func() {}
+	}
+	return s.quotas.Lock(tenantName)
+}
+
+// taskCount is the connector's tasks.max, Kafka Connect defaults it to 1
+func taskCount(config map[string]string) int {
+	if n, err := strconv.Atoi(config["tasks.max"]); err == nil && n > 0 {
+		return n
+	}
+	return 1
+}
+
+// Tenant limits and current usage
+func (s *cDCRegistrationService) GetTenantUsage(ctx context.Context, tenantName string) (*models.TenantUsage, error) {
+	t := s.tenants.Lookup(tenantName)
+	if t == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, tenantName)
+	}
+
+	connectors, err := s.repo.FindActiveByTenant(ctx, tenantName)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &models.TenantUsage{
+		Tenant:        t.Name,
+		Connectors:    len(connectors),
+		MaxConnectors: t.MaxConnectors,
+		MaxTasks:      t.MaxTasks,
+	}
+	for _, c := range connectors {
+		usage.Tasks += taskCount(c.Config)
+	}
+	return usage, nil
+}
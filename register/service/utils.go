@@ -1,8 +1,14 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"go.uber.org/zap"
+	"net/url"
 	"register/models"
+	"register/pkg/connect"
+	"register/pkg/redact"
+	"sort"
 	"strings"
 )
 
@@ -17,8 +23,9 @@ func (s *cDCRegistrationService) buildConnectorConfig(req models.RegisterConnect
 
 	var config map[string]interface{}
 
-	switch strings.ToLower(string(req.DatabaseType)) {
-	case "mysql":
+	databaseType, _ := models.ParseDatabase(string(req.DatabaseType))
+	switch databaseType {
+	case models.MYSQL:
 		config = map[string]interface{}{
 			"name": req.ConnectorName,
 			"config": map[string]interface{}{
@@ -40,7 +47,7 @@ func (s *cDCRegistrationService) buildConnectorConfig(req models.RegisterConnect
 			},
 		}
 
-	case "postgresql":
+	case models.POSTGRES:
 		config = map[string]interface{}{
 			"name": req.ConnectorName,
 			"config": map[string]interface{}{
@@ -59,14 +66,103 @@ func (s *cDCRegistrationService) buildConnectorConfig(req models.RegisterConnect
 			},
 		}
 
+	case models.SQLSERVER:
+		schema := req.Schema
+		if schema == "" {
+			schema = "dbo"
+		}
+		config = map[string]interface{}{
+			"name": req.ConnectorName,
+			"config": map[string]interface{}{
+				"connector.class":                 "io.debezium.connector.sqlserver.SqlServerConnector",
+				"database.hostname":               req.DatabaseHost,
+				"database.port":                   fmt.Sprintf("%d", req.DatabasePort),
+				"database.user":                   req.Username,
+				"database.password":               req.Password,
+				"database.names":                  req.DatabaseName,
+				"database.encrypt":                fmt.Sprintf("%t", req.Encrypt),
+				"database.trustServerCertificate": fmt.Sprintf("%t", req.TrustServerCertificate),
+				"topic.prefix":                    req.TopicPrefix,
+				"table.include.list":              s.formatTableList(schema, req.Tables), // SQL Server tables are schema qualified
+				"schema.history.internal.kafka.bootstrap.servers": "kafka:9092",
+				"schema.history.internal.kafka.topic":             fmt.Sprintf("schemahistory.%s.%s", req.TopicPrefix, req.DatabaseName),
+				"snapshot.mode":                                   req.SnapshotMode,
+				"decimal.handling.mode":                           "string",
+				"time.precision.mode":                             "connect",
+				"include.schema.changes":                          "true",
+			},
+		}
+
+	case models.MONGODB:
+		captureMode := req.CaptureMode
+		if captureMode == "" {
+			captureMode = "change_streams_update_full"
+		}
+		connectorConfig := map[string]interface{}{
+			"connector.class":           "io.debezium.connector.mongodb.MongoDbConnector",
+			"mongodb.connection.string": req.ConnectionString,
+			"topic.prefix":              req.TopicPrefix,
+			"database.include.list":     req.DatabaseName,
+			"collection.include.list":   s.formatTableList(req.DatabaseName, req.Tables), // collections are db.collection
+			"capture.mode":              captureMode,
+			"snapshot.mode":             req.SnapshotMode,
+		}
+		// Credentials are optional when they are part of the connection string
+		if req.Username != "" {
+			connectorConfig["mongodb.user"] = req.Username
+			connectorConfig["mongodb.password"] = req.Password
+		}
+		config = map[string]interface{}{
+			"name":   req.ConnectorName,
+			"config": connectorConfig,
+		}
+
+	case models.ORACLE:
+		schema := req.Schema
+		if schema == "" {
+			schema = strings.ToUpper(req.Username) // Oracle tables live in the user's schema by default
+		}
+		strategy := req.LogMiningStrategy
+		if strategy == "" {
+			strategy = "online_catalog"
+		}
+		connectorConfig := map[string]interface{}{
+			"connector.class":             "io.debezium.connector.oracle.OracleConnector",
+			"database.hostname":           req.DatabaseHost,
+			"database.port":               fmt.Sprintf("%d", req.DatabasePort),
+			"database.user":               req.Username,
+			"database.password":           req.Password,
+			"database.dbname":             req.DatabaseName,
+			"database.connection.adapter": "logminer",
+			"log.mining.strategy":         strategy,
+			"topic.prefix":                req.TopicPrefix,
+			"table.include.list":          s.formatTableList(schema, req.Tables),
+			"schema.history.internal.kafka.bootstrap.servers": "kafka:9092",
+			"schema.history.internal.kafka.topic":             fmt.Sprintf("schemahistory.%s.%s", req.TopicPrefix, req.DatabaseName),
+			"snapshot.mode":                                   req.SnapshotMode,
+			"decimal.handling.mode":                           "string",
+			"time.precision.mode":                             "connect",
+			"include.schema.changes":                          "true",
+		}
+		if req.PDBName != "" {
+			connectorConfig["database.pdb.name"] = req.PDBName
+		}
+		config = map[string]interface{}{
+			"name":   req.ConnectorName,
+			"config": connectorConfig,
+		}
+
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", req.DatabaseType)
 	}
 
-	// Add custom transforms if provided
+	// Add custom transforms if provided, they must not override the built config
 	if req.Transforms != nil && len(req.Transforms) > 0 {
 		configMap := config["config"].(map[string]interface{})
 		for key, value := range req.Transforms {
+			if !models.ValidTransformKey(key) {
+				return nil, fmt.Errorf("transforms key %q is not a transform or predicate setting", key)
+			}
 			configMap[key] = value
 		}
 	}
@@ -86,15 +182,141 @@ func (s *cDCRegistrationService) formatTableList(database string, tables []strin
 	return strings.Join(formattedTables, ",")
 }
 
-func (s *cDCRegistrationService) getConnectorStatus(connectorName string) (*models.ConnectorStatus, error) {
-	url := fmt.Sprintf("%s/connectors/%s/status", s.cfg.ConnectorUrl, connectorName)
-
-	var status models.ConnectorStatus
-	if err := s.client.Get(url, &status); err != nil {
+func (s *cDCRegistrationService) getConnectorStatus(ctx context.Context, connectorName string) (*models.ConnectorStatus, error) {
+	status, err := s.connect.ConnectorStatus(ctx, connectorName, s.policy(connectRead))
+	if err != nil {
 		return nil, fmt.Errorf("failed to get connector status for %s: %w", connectorName, err)
 	}
 
-	return &status, nil
+	return toConnectorStatus(status), nil
+}
+
+// toConnectorStatus converts a Kafka Connect status into its API model
+func toConnectorStatus(status *connect.ConnectorStatus) *models.ConnectorStatus {
+	converted := &models.ConnectorStatus{
+		Name: status.Name,
+		Type: status.Type,
+		Connector: models.ConnectorState{
+			State:    status.Connector.State,
+			WorkerID: status.Connector.WorkerID,
+			Trace:    status.Connector.Trace,
+		},
+		Tasks: make([]models.TaskState, 0, len(status.Tasks)),
+	}
+	for _, task := range status.Tasks {
+		converted.Tasks = append(converted.Tasks, models.TaskState{
+			ID:       task.ID,
+			State:    task.State,
+			WorkerID: task.WorkerID,
+			Trace:    task.Trace,
+		})
+	}
+	return converted
+}
+
+// recordConnector writes the registered connector to the registry. Kafka Connect
+// already accepted the connector at this point, so failures are only logged.
+// Credentials are masked, only secret store placeholders are kept.
+func (s *cDCRegistrationService) recordConnector(ctx context.Context, req models.RegisterConnectorRequest, config map[string]string, status string) {
+	// MongoDB connects with a connection string, record its hosts instead
+	if req.DatabaseType == models.MONGODB {
+		if host := connectionStringHost(req.ConnectionString); host != "" {
+			req.DatabaseHost = host
+		}
+	}
+
+	record := &models.Connector{
+		ConnectorName:          req.ConnectorName,
+		DatabaseType:           string(req.DatabaseType),
+		DatabaseHost:           req.DatabaseHost,
+		DatabasePort:           req.DatabasePort,
+		DatabaseName:           req.DatabaseName,
+		TopicPrefix:            req.TopicPrefix,
+		Username:               req.Username,
+		Tables:                 req.Tables,
+		SnapshotMode:           req.SnapshotMode,
+		ServerID:               req.ServerID,
+		Schema:                 req.Schema,
+		Encrypt:                req.Encrypt,
+		TrustServerCertificate: req.TrustServerCertificate,
+		CaptureMode:            req.CaptureMode,
+		PDBName:                req.PDBName,
+		LogMiningStrategy:      req.LogMiningStrategy,
+		Transforms:             req.Transforms,
+		Status:                 status,
+		Config:                 s.redact.Map(config),
+		CreatedBy:              req.CreatedBy,
+		Tenant:                 req.Tenant,
+	}
+
+	if err := s.repo.Save(ctx, record); err != nil {
+		s.log.Error("Failed to record connector in registry", zap.String("connector", req.ConnectorName), zap.Error(err))
+	}
+}
+
+// applyUpdate overlays the fields set in an update request onto a registration request
+func applyUpdate(req *models.RegisterConnectorRequest, update models.UpdateConnectorRequest) {
+	if update.DatabaseHost != "" {
+		req.DatabaseHost = update.DatabaseHost
+	}
+	if update.DatabasePort != 0 {
+		req.DatabasePort = update.DatabasePort
+	}
+	if update.DatabaseName != "" {
+		req.DatabaseName = update.DatabaseName
+	}
+	if update.Username != "" {
+		req.Username = update.Username
+	}
+	if update.Password != "" {
+		req.Password = update.Password
+	}
+	if update.TopicPrefix != "" {
+		req.TopicPrefix = update.TopicPrefix
+	}
+	if len(update.Tables) > 0 {
+		req.Tables = update.Tables
+	}
+	if update.SnapshotMode != "" {
+		req.SnapshotMode = update.SnapshotMode
+	}
+	if update.ServerID != 0 {
+		req.ServerID = update.ServerID
+	}
+	if update.Schema != "" {
+		req.Schema = update.Schema
+	}
+	if update.Encrypt != nil {
+		req.Encrypt = *update.Encrypt
+	}
+	if update.TrustServerCertificate != nil {
+		req.TrustServerCertificate = *update.TrustServerCertificate
+	}
+	if update.ConnectionString != "" {
+		req.ConnectionString = update.ConnectionString
+	}
+	if update.CaptureMode != "" {
+		req.CaptureMode = update.CaptureMode
+	}
+	if update.PDBName != "" {
+		req.PDBName = update.PDBName
+	}
+	if update.LogMiningStrategy != "" {
+		req.LogMiningStrategy = update.LogMiningStrategy
+	}
+	if update.Transforms != nil {
+		req.Transforms = update.Transforms
+	}
+}
+
+// connectionStringHost returns the hosts of a MongoDB connection string without
+// its credentials, empty for a secret store placeholder
+func connectionStringHost(connectionString string) string {
+	u, err := url.Parse(connectionString)
+	if err != nil {
+		return ""
+	}
+	return u.Host
 }
 
 func flattenConfig(config map[string]interface{}) map[string]string {
@@ -104,3 +326,36 @@ func flattenConfig(config map[string]interface{}) map[string]string {
 	}
 	return result
 }
+
+// diffConfig compares desired and actual connector configs key by key. The
+// "name" key is ignored because Kafka Connect adds it to every config, and
+// masked desired values match any actual value.
+func diffConfig(desired, actual map[string]string) []models.ConfigDifference {
+	keys := make(map[string]bool)
+	for k := range desired {
+		keys[k] = true
+	}
+	for k := range actual {
+		keys[k] = true
+	}
+	delete(keys, "name")
+
+	var differences []models.ConfigDifference
+	for k := range keys {
+		desiredValue, inDesired := desired[k]
+		actualValue, inActual := actual[k]
+		if inDesired && inActual && (desiredValue == actualValue || desiredValue == redact.Mask) {
+			continue
+		}
+		differences = append(differences, models.ConfigDifference{
+			Key:     k,
+			Desired: desiredValue,
+			Actual:  actualValue,
+		})
+	}
+
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Key < differences[j].Key
+	})
+	return differences
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"register/models"
+)
+
+// ValidationError is returned when Kafka Connect rejects a connector config
+type ValidationError struct {
+	Result *models.ValidationResult
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("connector config has %d validation error(s)", e.Result.ErrorCount)
+}
+
+// Validate connector config without creating it
+func (s *cDCRegistrationService) ValidateConnector(ctx context.Context, req models.RegisterConnectorRequest) (*models.ValidationResult, error) {
+	config, err := s.buildConnectorConfig(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build connector config: %w", err)
+	}
+
+	return s.validateConfig(ctx, req.ConnectorName, config["config"].(map[string]interface{}))
+}
+
+func (s *cDCRegistrationService) validateConfig(ctx context.Context, connectorName string, config map[string]interface{}) (*models.ValidationResult, error) {
+	connectorClass := fmt.Sprintf("%v", config["connector.class"])
+	body := flattenConfig(config)
+	body["name"] = connectorName
+
+	resp, err := s.connect.ValidateConfig(ctx, connectorClass, body, s.policy(connectValidate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate connector config: %w", err)
+	}
+
+	result := &models.ValidationResult{
+		ConnectorName:  connectorName,
+		ConnectorClass: connectorClass,
+		Valid:          resp.ErrorCount == 0,
+		ErrorCount:     resp.ErrorCount,
+		Errors:         []models.FieldValidationError{},
+	}
+	for _, c := range resp.Configs {
+		if len(c.Value.Errors) > 0 {
+			messages := make([]string, len(c.Value.Errors))
+			for i, message := range c.Value.Errors {
+				messages[i] = s.redact.Text(message)
+			}
+			result.Errors = append(result.Errors, models.FieldValidationError{
+				Field:  c.Value.Name,
+				Errors: messages,
+			})
+		}
+	}
+
+	if !result.Valid {
+		s.log.Warn("Connector config failed validation",
+			zap.String("connector", connectorName),
+			zap.Int("error_count", result.ErrorCount),
+		)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"register/models"
+	"register/pkg/logger"
+	"register/pkg/webhook"
+	"time"
+)
+
+type StatusWatcher interface {
+	Start(ctx context.Context)
+}
+
+type statusWatcher struct {
+	service  CDCRegistrationService
+	webhooks webhook.Dispatcher
+	interval time.Duration
+	log      logger.Logger
+
+	// last connector and task states seen, by connector name
+	connectors map[string]string
+	tasks      map[string]map[int]string
+}
+
+// NewStatusWatcher polls the status of every connector and dispatches a
+// webhook event for each connector or task state transition
+func NewStatusWatcher(service CDCRegistrationService, webhooks webhook.Dispatcher, interval time.Duration, log logger.Logger) StatusWatcher {
+	return &statusWatcher{
+		service:    service,
+		webhooks:   webhooks,
+		interval:   interval,
+		log:        log,
+		connectors: make(map[string]string),
+		tasks:      make(map[string]map[int]string),
+	}
+}
+
+// Start dispatches a webhook event for every connector and task state change
+func (w *statusWatcher) Start(ctx context.Context) {
+	w.log.Info("Starting status watcher", zap.String("interval", w.interval.String()))
+
+	runEvery(ctx, w.interval, w.poll)
+	w.log.Info("Stopping status watcher")
+}
+
+func (w *statusWatcher) poll(ctx context.Context) {
+	// Connectors keep their last state when the statuses cannot be read, their
+	// next transition is compared against that
+	statuses, err := w.service.ConnectorStatuses(ctx)
+	if err != nil {
+		w.log.Error("Status watcher failed to get connector statuses", zap.Error(err))
+		return
+	}
+
+	for _, status := range statuses {
+		if status != nil {
+			w.observe(status)
+		}
+	}
+
+	// Forget deleted connectors so a re-registered one starts fresh
+	for name := range w.connectors {
+		if _, ok := statuses[name]; !ok {
+			delete(w.connectors, name)
+			delete(w.tasks, name)
+		}
+	}
+}
+
+// observe records status and dispatches its transitions. The first status of
+// a connector is only recorded, there is nothing to compare it with.
+func (w *statusWatcher) observe(status *models.ConnectorStatus) {
+	name := status.Name
+	previous, known := w.connectors[name]
+	previousTasks := w.tasks[name]
+
+	w.connectors[name] = status.Connector.State
+	w.tasks[name] = make(map[int]string, len(status.Tasks))
+	for _, task := range status.Tasks {
+		w.tasks[name][task.ID] = task.State
+	}
+
+	if !known {
+		return
+	}
+
+	if previous != status.Connector.State {
+		w.dispatch(webhook.Event{
+			Type:      webhook.ConnectorStateChanged,
+			Connector: name,
+			From:      previous,
+			To:        status.Connector.State,
+			WorkerID:  status.Connector.WorkerID,
+			Trace:     status.Connector.Trace,
+		})
+	}
+
+	for _, task := range status.Tasks {
+		from, ok := previousTasks[task.ID]
+		if ok && from == task.State {
+			continue
+		}
+		if !ok {
+			from = "UNASSIGNED"
+		}
+		taskID := task.ID
+		w.dispatch(webhook.Event{
+			Type:      webhook.TaskStateChanged,
+			Connector: name,
+			TaskID:    &taskID,
+			From:      from,
+			To:        task.State,
+			WorkerID:  task.WorkerID,
+			Trace:     task.Trace,
+		})
+	}
+}
+
+func (w *statusWatcher) dispatch(event webhook.Event) {
+	id, err := newID()
+	if err != nil {
+		w.log.Error("Failed to create webhook event", zap.Error(err))
+		return
+	}
+	event.ID = id
+	event.Timestamp = time.Now().Format(time.RFC3339)
+
+	w.log.Info("Connector state changed",
+		zap.String("connector", event.Connector),
+		zap.String("type", event.Type),
+		zap.String("from", event.From),
+		zap.String("to", event.To),
+	)
+	w.webhooks.Dispatch(event)
+}